@@ -274,9 +274,8 @@ func getRepositoryData() ([]RepositoryData, error) {
 			}
 			if json.Unmarshal(langData, &byLang) == nil {
 				for _, r := range byLang.Results {
-					if strings.TrimSpace(r.Language) == utils.LanguageExcludedFromTotalLOC {
-						codeLinesForReport = reportData.TotalCodeLines - r.CodeLines
-						break
+					if utils.IsExcludedFromTotalLOC(r.Language) {
+						codeLinesForReport -= r.CodeLines
 					}
 				}
 			}
@@ -629,9 +628,8 @@ func getRepositoryDetailData(repoName, branchName string) (*RepositoryDetailData
 	// Code lines for report total: exclude JSON to match SonarQube behavior
 	totalCodeLinesForReport := byFileReport.TotalCodeLines
 	for _, lang := range languageReport.Results {
-		if strings.TrimSpace(lang.Language) == utils.LanguageExcludedFromTotalLOC {
-			totalCodeLinesForReport = byFileReport.TotalCodeLines - lang.CodeLines
-			break
+		if utils.IsExcludedFromTotalLOC(lang.Language) {
+			totalCodeLinesForReport -= lang.CodeLines
 		}
 	}
 
@@ -656,7 +654,7 @@ func getRepositoryDetailData(repoName, branchName string) (*RepositoryDetailData
 		Platform:         platform,
 		PlatformIcon:     platformIcon,
 		RepositoryURL:    repositoryURL,
-		NoteLOCExcluded:  utils.NoteExcludedFromTotal,
+		NoteLOCExcluded:  utils.NoteExcludedFromTotal(),
 	}
 
 	return repoDetail, nil
@@ -763,7 +761,7 @@ func loadApplicationData() (PageData, error) {
 	totalLinesExcludingJSON := 0
 	for lang, total := range ligneDeCodeParLangage {
 		totalLines += total
-		if strings.TrimSpace(lang) != utils.LanguageExcludedFromTotalLOC {
+		if !utils.IsExcludedFromTotalLOC(lang) {
 			totalLinesExcludingJSON += total
 		}
 		languages = append(languages, LanguageData{
@@ -775,7 +773,7 @@ func loadApplicationData() (PageData, error) {
 
 	// Percentages use total excluding JSON to match SonarQube behavior
 	for i := range languages {
-		if strings.TrimSpace(languages[i].Language) == utils.LanguageExcludedFromTotalLOC {
+		if utils.IsExcludedFromTotalLOC(languages[i].Language) {
 			languages[i].Percentage = 0
 		} else if totalLinesExcludingJSON > 0 {
 			languages[i].Percentage = float64(languages[i].CodeLines) / float64(totalLinesExcludingJSON) * 100
@@ -805,7 +803,7 @@ func loadApplicationData() (PageData, error) {
 		Languages:       languages,
 		GlobalReport:    globalInfo,
 		Repositories:    repositoryData,
-		NoteLOCExcluded: utils.NoteExcludedFromTotal,
+		NoteLOCExcluded: utils.NoteExcludedFromTotal(),
 	}
 
 	return pageData, nil
@@ -926,7 +924,33 @@ func handlePortConflict(port int) {
 	}
 }
 
+// loadExcludedLanguages reads the languages left out of the totals from the
+// license settings of the GoLC config file, as golc does. The default is
+// kept when the file cannot be read.
+func loadExcludedLanguages() {
+	configPath := os.Getenv("GOLC_CONFIG_FILE")
+	if configPath == "" {
+		configPath = "config.json"
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return
+	}
+
+	var config struct {
+		License struct {
+			ExcludedLanguages []string `json:"excludedLanguages"`
+		} `json:"license"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		fmt.Printf("❌ error decoding %s: %v\n", configPath, err)
+		return
+	}
+	utils.SetLanguagesExcludedFromTotalLOC(config.License.ExcludedLanguages)
+}
+
 func main() {
+	loadExcludedLanguages()
 	pageData, err := loadApplicationData()
 	if err != nil {
 		fmt.Println("❌", err)
@@ -7,211 +7,429 @@ var Languages = language.Languages{
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".as"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "flex",
+		Edition:           language.EditionCommunity,
 	},
 	"Abap": {
 		LineComments:      []string{"*", "\""},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".abap", ".ab4", ".flow", ".asprog"},
+		StringDelimiters:  []string{"'"},
+		SonarKey:          "abap",
+		Edition:           language.EditionEnterprise,
 	},
 	"Apex": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".cls", ".trigger"},
+		StringDelimiters:  []string{"'"},
+		TestPatterns:      []string{"*Test.cls", "*_Test.cls", "*Tests.cls"},
+		SonarKey:          "apex",
+		Edition:           language.EditionEnterprise,
+	},
+	"Bicep": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		Extensions:        []string{".bicep"},
+		StringDelimiters:  []string{"'"},
+		MultiLineStrings:  [][]string{{"'''", "'''"}},
+		SonarKey:          "azureresourcemanager",
+		Edition:           language.EditionCommunity,
 	},
 	"C": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".c"},
+		SonarKey:          "c",
+		Edition:           language.EditionDeveloper,
 	},
 	"C Header": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".h"},
+		SonarKey:          "c",
+		Edition:           language.EditionDeveloper,
 	},
 	"C++": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".cpp", ".cc"},
+		SonarKey:          "cpp",
+		Edition:           language.EditionDeveloper,
 	},
 	"C++ Header": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
-		Extensions:        []string{".hh", ".hpp"},
+		Extensions:        []string{".hh", ".hpp", ".h"},
+		SonarKey:          "cpp",
+		Edition:           language.EditionDeveloper,
 	},
 	"COBOL": {
 		LineComments:      []string{"*"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".cbl", ".ccp", ".cob", ".cobol", ".cpy"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "cobol",
+		Edition:           language.EditionEnterprise,
 	},
 	"C#": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".cs"},
+		TestPatterns:      []string{"*.Tests/", "*.Test/", "*Tests.cs", "*Test.cs"},
+		SonarKey:          "cs",
+		Edition:           language.EditionCommunity,
 	},
 	"CSS": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".css"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "css",
+		Edition:           language.EditionCommunity,
 	},
 	"Dart": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".dart"},
+		NestedComments:    true,
+		StringDelimiters:  []string{"\"", "'"},
+		TestPatterns:      []string{"test/", "*_test.dart"},
+		SonarKey:          "dart",
+		Edition:           language.EditionDeveloper,
+	},
+	"Elixir": {
+		LineComments:      []string{"#"},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".ex", ".exs"},
+		StringDelimiters:  []string{"\"", "'"},
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}, {"'''", "'''"}},
+		TestPatterns:      []string{"test/", "*_test.exs"},
 	},
 	"Golang": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".go"},
+		MultiLineStrings:  [][]string{{"`", "`", language.RawString}},
+		TestPatterns:      []string{"*_test.go"},
+		SonarKey:          "go",
+		Edition:           language.EditionCommunity,
+	},
+	"Go Template": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{{"{{/*", "*/}}"}, {"{{- /*", "*/ -}}"}},
+		Extensions:        []string{".gotmpl", ".gohtml", ".tmpl"},
+	},
+	"Groovy": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		Extensions:        []string{".groovy", ".gvy", ".gy", ".gsh"},
+		FileNames:         []string{"Jenkinsfile", "Jenkinsfile.*", "*.jenkinsfile"},
+		Shebangs:          []string{"groovy"},
+		StringDelimiters:  []string{"\"", "'"},
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}, {"'''", "'''"}},
+		TestPatterns:      []string{"src/test/", "*Spec.groovy", "*Test.groovy"},
 	},
 	"HTML": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
-		Extensions:        []string{".html", ".htm", ".cshtml", ".vbhtml", ".aspx", ".ascx", ".rhtml", ".erb", ".shtml", ".shtm", ".cmp"},
+		Extensions:        []string{".html", ".htm", ".vbhtml", ".aspx", ".ascx", ".rhtml", ".erb", ".shtml", ".shtm", ".cmp"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "web",
+		Edition:           language.EditionCommunity,
 	},
 	"Java": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".java", ".jav"},
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
+		TestPatterns:      []string{"src/test/", "*Test.java", "*Tests.java", "*IT.java"},
+		SonarKey:          "java",
+		Edition:           language.EditionCommunity,
 	},
 	"JavaScript": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".js", ".jsx", ".jsp", ".jspf"},
+		Shebangs:          []string{"node"},
+		StringDelimiters:  []string{"\"", "'"},
+		MultiLineStrings:  [][]string{{"`", "`"}},
+		TestPatterns:      []string{"__tests__/", "*.test.js", "*.spec.js", "*.test.jsx", "*.spec.jsx"},
+		SonarKey:          "js",
+		Edition:           language.EditionCommunity,
 	},
 	"Kotlin": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".kt", ".kts"},
+		NestedComments:    true,
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
+		TestPatterns:      []string{"src/test/", "src/androidTest/", "*Test.kt", "*Tests.kt"},
+		SonarKey:          "kotlin",
+		Edition:           language.EditionCommunity,
+	},
+	"Makefile": {
+		LineComments:      []string{"#"},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".mk", ".mak"},
+		FileNames:         []string{"Makefile", "makefile", "GNUmakefile", "Makefile.*"},
+		Shebangs:          []string{"make"},
+		StringDelimiters:  []string{"\"", "'"},
 	},
 	"Flex": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".as"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "flex",
+		Edition:           language.EditionCommunity,
 	},
 	"PHP": {
 		LineComments:      []string{"//", "#"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".php", ".php3", ".php4", ".php5", ".phtml", ".inc"},
+		Shebangs:          []string{"php"},
+		StringDelimiters:  []string{"\"", "'"},
+		TestPatterns:      []string{"tests/", "*Test.php"},
+		SonarKey:          "php",
+		Edition:           language.EditionCommunity,
+	},
+	"Perl": {
+		LineComments:      []string{"#"},
+		MultiLineComments: [][]string{{"=pod", "=cut"}, {"=head1", "=cut"}, {"=begin", "=cut"}},
+		Extensions:        []string{".pl", ".pm", ".t"},
+		Shebangs:          []string{"perl"},
+		StringDelimiters:  []string{"\"", "'"},
+		TestPatterns:      []string{"t/", "*.t"},
 	},
 	"Objective-C": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
-		Extensions:        []string{".m", ".mm"},
+		Extensions:        []string{".m", ".mm", ".h"},
+		SonarKey:          "objc",
+		Edition:           language.EditionDeveloper,
+	},
+	"Jupyter Notebook": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".ipynb"},
+		Notebook:          true,
+		SonarKey:          "ipynb",
+		Edition:           language.EditionCommunity,
 	},
 	"JSON": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".json"},
+		SonarKey:          "json",
+		Edition:           language.EditionCommunity,
 	},
 	"Shell": {
 		LineComments:      []string{"#"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".sh", ".bash", ".zsh", ".ksh"},
+		Shebangs:          []string{"sh", "bash", "zsh", "ksh", "dash"},
+		StringDelimiters:  []string{"\"", "'"},
 	},
 	"Docker": {
 		LineComments:      []string{"#"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{"Dockerfile", "dockerfile"},
+		FileNames:         []string{"Dockerfile", "Dockerfile.*", "*.dockerfile", "Containerfile"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "docker",
+		Edition:           language.EditionCommunity,
 	},
 	"Oracle PL/SQL": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
-		Extensions:        []string{".pkb"},
+		Extensions:        []string{".pkb", ".pks", ".pls", ".plb", ".pck", ".pkg", ".fnc", ".prc", ".trg", ".tpb", ".tps"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "plsql",
+		Edition:           language.EditionDeveloper,
 	},
 	"PL/I": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".pl1", ".pli"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "pli",
+		Edition:           language.EditionEnterprise,
 	},
 	"Python": {
 		LineComments:      []string{"#"},
-		MultiLineComments: [][]string{{"\"\"\"", "\"\"\""}, {"'''", "'''"}},
+		MultiLineComments: [][]string{},
 		Extensions:        []string{".py"},
+		Shebangs:          []string{"python"},
+		StringDelimiters:  []string{"\"", "'"},
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}, {"'''", "'''"}},
+		DocStringScopes:   []string{"def ", "async def ", "class "},
+		TestPatterns:      []string{"tests/", "test_*.py", "*_test.py", "conftest.py"},
+		SonarKey:          "py",
+		Edition:           language.EditionCommunity,
 	},
 
 	"RPG": {
 		LineComments:      []string{"*"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".rpg"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "rpg",
+		Edition:           language.EditionEnterprise,
+	},
+	"Razor": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{{"@*", "*@"}, {"<!--", "-->"}},
+		Extensions:        []string{".cshtml", ".razor"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "cs",
+		Edition:           language.EditionCommunity,
 	},
 	"Ruby": {
 		LineComments:      []string{"#"},
 		MultiLineComments: [][]string{{"=begin", "=end"}},
 		Extensions:        []string{".rb"},
+		FileNames:         []string{"Vagrantfile", "Gemfile", "Rakefile"},
+		Shebangs:          []string{"ruby"},
+		StringDelimiters:  []string{"\"", "'"},
+		TestPatterns:      []string{"spec/", "test/", "*_spec.rb", "*_test.rb"},
+		SonarKey:          "ruby",
+		Edition:           language.EditionCommunity,
 	},
 	"Scala": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".scala"},
+		NestedComments:    true,
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
+		TestPatterns:      []string{"src/test/", "*Spec.scala", "*Test.scala", "*Suite.scala"},
+		SonarKey:          "scala",
+		Edition:           language.EditionCommunity,
 	},
 	"Rust": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".rs"},
+		NestedComments:    true,
+		TestPatterns:      []string{"tests/"},
+		SonarKey:          "rust",
+		Edition:           language.EditionDeveloper,
 	},
 	"Scss": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".scss"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "css",
+		Edition:           language.EditionCommunity,
 	},
 	"SQL": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".sql"},
+		StringDelimiters:  []string{"\"", "'"},
+	},
+	"Svelte": {
+		LineComments:      []string{},
+		MultiLineComments: [][]string{{"<!--", "-->"}},
+		Extensions:        []string{".svelte"},
+		StringDelimiters:  []string{"\"", "'"},
 	},
 	"Swift": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".swift"},
+		NestedComments:    true,
+		MultiLineStrings:  [][]string{{"\"\"\"", "\"\"\""}},
+		TestPatterns:      []string{"Tests/", "*Tests.swift"},
+		SonarKey:          "swift",
+		Edition:           language.EditionDeveloper,
 	},
 	"TypeScript": {
 		LineComments:      []string{"//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".ts", ".tsx"},
+		StringDelimiters:  []string{"\"", "'"},
+		MultiLineStrings:  [][]string{{"`", "`"}},
+		TestPatterns:      []string{"__tests__/", "*.test.ts", "*.spec.ts", "*.test.tsx", "*.spec.tsx"},
+		SonarKey:          "ts",
+		Edition:           language.EditionCommunity,
 	},
 	"T-SQL": {
 		LineComments:      []string{"--"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".tsql"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "tsql",
+		Edition:           language.EditionDeveloper,
 	},
 	"Vue": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
 		Extensions:        []string{".vue"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "js",
+		Edition:           language.EditionCommunity,
 	},
 	"Visual Basic .NET": {
 		LineComments:      []string{"'"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".vb"},
+		SonarKey:          "vbnet",
+		Edition:           language.EditionCommunity,
+	},
+	"Visual Basic": {
+		LineComments:      []string{"'"},
+		MultiLineComments: [][]string{},
+		Extensions:        []string{".vbs", ".vba"},
+		SonarKey:          "vb",
+		Edition:           language.EditionEnterprise,
 	},
 	"VB6": {
 		LineComments:      []string{"'"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".bas", ".frm", ".cls"},
+		SonarKey:          "vb",
+		Edition:           language.EditionEnterprise,
 	},
 	"XML": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
 		Extensions:        []string{".xml", ".XML"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "xml",
+		Edition:           language.EditionCommunity,
 	},
 	"XHTML": {
 		LineComments:      []string{},
 		MultiLineComments: [][]string{{"<!--", "-->"}},
 		Extensions:        []string{".xhtml"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "web",
+		Edition:           language.EditionCommunity,
 	},
 	"YAML": {
 		LineComments:      []string{"#"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".yaml", ".yml"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "yaml",
+		Edition:           language.EditionCommunity,
 	},
 	"Terraform": {
 		LineComments:      []string{"#", "//"},
 		MultiLineComments: [][]string{{"/*", "*/"}},
 		Extensions:        []string{".tf"},
+		SonarKey:          "terraform",
+		Edition:           language.EditionCommunity,
 	},
 	"JCL": {
 		LineComments:      []string{"//*"},
 		MultiLineComments: [][]string{},
 		Extensions:        []string{".jcl", ".JCL"},
+		StringDelimiters:  []string{"\"", "'"},
+		SonarKey:          "jcl",
+		Edition:           language.EditionEnterprise,
 	},
 }
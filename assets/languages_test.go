@@ -0,0 +1,36 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+func TestLanguagesSonarMetadata(t *testing.T) {
+	editions := map[string]bool{
+		language.EditionCommunity:  true,
+		language.EditionDeveloper:  true,
+		language.EditionEnterprise: true,
+	}
+
+	for name, info := range Languages {
+		if info.SonarKey == "" && info.Edition != "" {
+			t.Errorf("%s has the edition %q but no Sonar key", name, info.Edition)
+		}
+		if info.SonarKey != "" && !editions[info.Edition] {
+			t.Errorf("%s has the Sonar key %q but the edition %q", name, info.SonarKey, info.Edition)
+		}
+	}
+
+	for _, name := range []string{"Oracle PL/SQL", "PL/I", "Visual Basic .NET", "VB6", "Bicep", "Visual Basic", "Razor"} {
+		if Languages[name].SonarKey == "" {
+			t.Errorf("%s should be tagged with its Sonar key", name)
+		}
+	}
+	// SonarQube has no analyzer of its own for these languages.
+	for _, name := range []string{"Groovy", "Go Template", "Perl", "Elixir"} {
+		if Languages[name].SonarKey != "" {
+			t.Errorf("%s should not be tagged with a Sonar key", name)
+		}
+	}
+}
@@ -14,14 +14,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/SonarSource-Demos/sonar-golc/assets"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/gogit"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
 	"github.com/briandowns/spinner"
 
 	"github.com/SonarSource-Demos/sonar-golc/pkg/devops/getazure"
@@ -35,10 +39,16 @@ import (
 type OrganizationData struct {
 	Organization           string `json:"Organization"`
 	TotalLinesOfCode       string `json:"TotalLinesOfCode"`
+	MainLinesOfCode        string `json:"MainLinesOfCode"`
+	TestLinesOfCode        string `json:"TestLinesOfCode"`
 	LargestRepository      string `json:"LargestRepository"`
 	LinesOfCodeLargestRepo string `json:"LinesOfCodeLargestRepo"`
 	DevOpsPlatform         string `json:"DevOpsPlatform"`
 	NumberRepos            int    `json:"NumberRepos"`
+	// LinesOfCodeByEdition splits the total by the first SonarQube edition analyzing each language
+	LinesOfCodeByEdition map[string]string `json:"LinesOfCodeByEdition,omitempty"`
+	// CachedRepos is the number of repositories served from the result cache
+	CachedRepos int64 `json:"CachedRepos,omitempty"`
 }
 
 type Repository struct {
@@ -66,9 +76,18 @@ type SelfLink struct {
 }
 
 type Config struct {
-	Platforms map[string]interface{} `json:"platforms"`
-	Logging   LoggingConfig          `json:"logging"`
-	Release   ReleaseConfig          `json:"release"`
+	Platforms     map[string]interface{} `json:"platforms"`
+	Logging       LoggingConfig          `json:"logging"`
+	Release       ReleaseConfig          `json:"release"`
+	Languages     language.Definitions   `json:"languages"`
+	LanguagesFile string                 `json:"languagesFile"`
+	License       LicenseConfig          `json:"license"`
+}
+
+// LicenseConfig sets the license tiers and the languages left out of the lines of code
+type LicenseConfig struct {
+	Tiers             []utils.LicenseTier `json:"tiers"`
+	ExcludedLanguages []string            `json:"excludedLanguages"`
 }
 
 type LoggingConfig struct {
@@ -89,21 +108,23 @@ type Report struct {
 }
 
 type Result struct {
-	TotalFiles      int           `json:"TotalFiles"`
-	TotalLines      int           `json:"TotalLines"`
-	TotalBlankLines int           `json:"TotalBlankLines"`
-	TotalComments   int           `json:"TotalComments"`
-	TotalCodeLines  int           `json:"TotalCodeLines"`
-	Results         []LanguageRes `json:"Results"`
+	TotalFiles         int           `json:"TotalFiles"`
+	TotalLines         int           `json:"TotalLines"`
+	TotalBlankLines    int           `json:"TotalBlankLines"`
+	TotalComments      int           `json:"TotalComments"`
+	TotalCodeLines     int           `json:"TotalCodeLines"`
+	TotalTestCodeLines int           `json:"TotalTestCodeLines"`
+	Results            []LanguageRes `json:"Results"`
 }
 
 type LanguageRes struct {
-	Language   string `json:"Language"`
-	Files      int    `json:"Files"`
-	Lines      int    `json:"Lines"`
-	BlankLines int    `json:"BlankLines"`
-	Comments   int    `json:"Comments"`
-	CodeLines  int    `json:"CodeLines"`
+	Language      string `json:"Language"`
+	Files         int    `json:"Files"`
+	Lines         int    `json:"Lines"`
+	BlankLines    int    `json:"BlankLines"`
+	Comments      int    `json:"Comments"`
+	CodeLines     int    `json:"CodeLines"`
+	TestCodeLines int    `json:"TestCodeLines"`
 }
 
 type RepoParams struct {
@@ -112,6 +133,37 @@ type RepoParams struct {
 	RepoSlug   string
 	MainBranch string
 	PathToScan string
+	// Credential authenticates the clone of PathToScan, which holds no secret.
+	Credential gogit.Credential
+}
+
+// AnalysisOptions holds the per-platform settings applied to every GCloc run
+type AnalysisOptions struct {
+	ScanWorkers   int
+	GitExclusions bool
+	GeneratedCode string
+	TestPatterns  []string
+	IncludePaths  []string
+	MaxFileSizeMB float64
+	MaxLineLength int
+	// InMemory analyzes the repositories from their git objects cloned in
+	// memory, without checking them out on disk.
+	InMemory bool
+	// RepoPaths are the path patterns of single repositories, by repository
+	// name or by project/repository.
+	RepoPaths map[string]PathRules
+	// SSH is the credential of the clones over SSH, of no kind when the
+	// repositories are cloned over HTTPS.
+	SSH gogit.Credential
+	// Platform names the platform in the keys of the clone cache.
+	Platform string
+}
+
+// PathRules are the include and exclude path patterns of a repository,
+// applied after those of its platform.
+type PathRules struct {
+	IncludePaths []string
+	ExcludePaths []string
 }
 
 type logWriter struct {
@@ -125,11 +177,32 @@ const errorMessageAnalyse = "\r❌ No Analysis performed...\n"
 const errorMessageRepos = "Error Get Info Repositories in organization '%s' : '%s'"
 const directoryconf = "/config"
 
+// The subdirectories of -cache-dir holding the clones and the reports
+const cloneCacheDir = "clones"
+const resultCacheDir = "results"
+
 var logFile *os.File
 var AppConfig Config
+var AppLanguages language.Languages
+
+// resultBranches maps the name of each result file to the repository and the branch analyzed
+var resultBranches = map[string]utils.ResultBranch{}
+var resultBranchesMu sync.Mutex
 var logger *logrus.Logger
+
+// repoCache keeps the clones of the repositories across runs, and
+// resultCache their reports, nil without the -cache-dir flag.
+var repoCache *gogit.Cache
+var resultCache *goloc.ResultCache
+
+// cachedResults counts the repositories served from the result cache
+var cachedResults atomic.Int64
 var version1 = "1.0.9"
 
+// languagesFiles are the language definition files looked up next to the
+// config file when it names none.
+var languagesFiles = []string{"languages.json", "languages.yaml", "languages.yml"}
+
 var directoriesToCreate = []string{
 	directoryconf,
 	"/byfile-report",
@@ -390,16 +463,118 @@ func AnalyseReposList(DestinationResult string, platformConfig map[string]interf
 	return len(repolist.([]interface{}))
 }
 
-func getExcludePaths(configValue interface{}) []string {
+// getStringList reads a list of strings of the configuration, such as the
+// path patterns of ExcludePaths or IncludePaths.
+func getStringList(configValue interface{}) []string {
 	if configValue == nil {
 		return []string{}
 	}
-	if excludePaths, ok := configValue.([]interface{}); ok {
-		return convertToSliceString(excludePaths)
+	if values, ok := configValue.([]interface{}); ok {
+		return convertToSliceString(values)
 	}
 	return []string{}
 }
 
+// getAnalysisOptions reads the optional analysis settings of a platform
+func getAnalysisOptions(platformConfig map[string]interface{}) AnalysisOptions {
+	var options AnalysisOptions
+	if workers, ok := platformConfig["ScanWorkers"].(float64); ok {
+		options.ScanWorkers = int(workers)
+	}
+	if gitExclusions, ok := platformConfig["GitExclusions"].(bool); ok {
+		options.GitExclusions = gitExclusions
+	}
+	if generatedCode, ok := platformConfig["GeneratedCode"].(string); ok {
+		options.GeneratedCode = generatedCode
+	}
+	if testPatterns, ok := platformConfig["TestPatterns"].([]interface{}); ok {
+		options.TestPatterns = convertToSliceString(testPatterns)
+	}
+	if maxFileSize, ok := platformConfig["MaxFileSizeMB"].(float64); ok {
+		options.MaxFileSizeMB = maxFileSize
+	}
+	if maxLineLength, ok := platformConfig["MaxLineLength"].(float64); ok {
+		options.MaxLineLength = int(maxLineLength)
+	}
+	if inMemory, ok := platformConfig["InMemory"].(bool); ok {
+		options.InMemory = inMemory
+	}
+	if includePaths, ok := platformConfig["IncludePaths"].([]interface{}); ok {
+		options.IncludePaths = convertToSliceString(includePaths)
+	}
+	if repoPaths, ok := platformConfig["RepoPaths"].(map[string]interface{}); ok {
+		options.RepoPaths = make(map[string]PathRules, len(repoPaths))
+		for repo, value := range repoPaths {
+			rules, _ := value.(map[string]interface{})
+			options.RepoPaths[repo] = PathRules{
+				IncludePaths: getStringList(rules["IncludePaths"]),
+				ExcludePaths: getStringList(rules["ExcludePaths"]),
+			}
+		}
+	}
+	options.SSH = getSSHCredential(platformConfig)
+	options.Platform, _ = platformConfig["DevOps"].(string)
+	return options
+}
+
+// getSSHCredential returns the credential of the clones over SSH: the key at
+// SSHKeyPath, whose passphrase is read from the environment variable named
+// by SSHKeyPassphraseEnv, or else ssh-agent when SSHAgent is set.
+func getSSHCredential(platformConfig map[string]interface{}) gogit.Credential {
+	var credential gogit.Credential
+	if keyPath, ok := platformConfig["SSHKeyPath"].(string); ok && keyPath != "" {
+		passphraseEnv, _ := platformConfig["SSHKeyPassphraseEnv"].(string)
+		credential = gogit.SSHKey(keyPath, os.Getenv(passphraseEnv))
+	} else if agent, ok := platformConfig["SSHAgent"].(bool); ok && agent {
+		credential = gogit.SSHAgent()
+	} else {
+		return credential
+	}
+	credential.KnownHosts, _ = platformConfig["SSHKnownHosts"].(string)
+
+	return credential
+}
+
+// useSSH clones the repository from its SSH clone link, when the platform
+// clones over SSH. Without a link, the repository is cloned over HTTPS.
+func (o AnalysisOptions) useSSH(params *RepoParams, sshURL string) {
+	if !o.SSH.IsSSH() {
+		return
+	}
+	if !gogit.IsSSHURL(sshURL) {
+		logger.Warnf("❗️ No SSH clone link for repository %s, cloned over HTTPS", params.RepoSlug)
+		return
+	}
+	params.PathToScan = sshURL
+	params.Credential = o.SSH
+}
+
+// apply copies the analysis options into the GCloc parameters
+func (o AnalysisOptions) apply(params *goloc.Params) {
+	params.ScanWorkers = o.ScanWorkers
+	params.GitExclusions = o.GitExclusions
+	params.GeneratedCode = o.GeneratedCode
+	params.TestPatterns = o.TestPatterns
+	params.IncludePaths = o.IncludePaths
+	params.MaxFileSize = int64(o.MaxFileSizeMB * 1024 * 1024)
+	params.MaxLineLength = o.MaxLineLength
+	params.InMemory = o.InMemory
+}
+
+// applyRepoPaths adds the path patterns of the repository, found under the
+// first of its names listed in RepoPaths, after those of the platform.
+func (o AnalysisOptions) applyRepoPaths(params *goloc.Params, names ...string) {
+	for _, name := range names {
+		rules, ok := o.RepoPaths[name]
+		if !ok {
+			continue
+		}
+		params.IncludePaths = append(append([]string{}, params.IncludePaths...), rules.IncludePaths...)
+		params.ExcludePaths = append(append([]string{}, params.ExcludePaths...), rules.ExcludePaths...)
+		return
+	}
+}
+
 // Analysis functions for different repository types
 
 // Analysis functions for Bitbucket Cloud
@@ -408,7 +583,7 @@ func analyseBitCRepo(project interface{}, DestinationResult string, platformConf
 	var excludeExtensions []string
 
 	excludeExtensions = convertToSliceString(platformConfig["ExtExclusion"].([]interface{}))
-	excludePath := getExcludePaths(platformConfig["ExcludePaths"])
+	excludePath := getStringList(platformConfig["ExcludePaths"])
 
 	// Determine git clone URL format
 	// For git operations with API tokens, use x-bitbucket-api-token-auth (static username for API tokens)
@@ -420,15 +595,15 @@ func analyseBitCRepo(project interface{}, DestinationResult string, platformConf
 		users = usersVal.(string)
 	}
 
-	var pathToScan string
+	var credential gogit.Credential
 	if users != "" && users != "XXXXX" {
 		// For git operations with API tokens, use x-bitbucket-api-token-auth as the username
 		// This is a static username that Bitbucket provides for API token authentication
 		// The actual Bitbucket username field in the API is the workspace ID, not suitable for git
-		pathToScan = fmt.Sprintf("%s://x-bitbucket-api-token-auth:%s@%s/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["AccessToken"].(string), platformConfig["Baseapi"].(string), workspace, p.RepoSlug)
+		credential = gogit.Token("x-bitbucket-api-token-auth", platformConfig["AccessToken"].(string))
 	} else {
 		// Use x-token-auth format for App Passwords (legacy)
-		pathToScan = fmt.Sprintf("%s://x-token-auth:%s@%s/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["AccessToken"].(string), platformConfig["Baseapi"].(string), workspace, p.RepoSlug)
+		credential = gogit.Token("x-token-auth", platformConfig["AccessToken"].(string))
 	}
 
 	params := RepoParams{
@@ -436,9 +611,10 @@ func analyseBitCRepo(project interface{}, DestinationResult string, platformConf
 		Namespace:  "",
 		RepoSlug:   p.RepoSlug,
 		MainBranch: p.MainBranch,
-		PathToScan: pathToScan,
+		PathToScan: fmt.Sprintf("%s://%s/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["Baseapi"].(string), workspace, p.RepoSlug),
+		Credential: credential,
 	}
-	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), getAnalysisOptions(platformConfig))
 }
 
 // Analysis functions for Bitbucket DC
@@ -447,16 +623,19 @@ func analyseBitSRVRepo(project interface{}, DestinationResult string, platformCo
 	var excludeExtensions []string
 
 	excludeExtensions = convertToSliceString(platformConfig["ExtExclusion"].([]interface{}))
-	excludePath := getExcludePaths(platformConfig["ExcludePaths"])
+	excludePath := getStringList(platformConfig["ExcludePaths"])
 
 	params := RepoParams{
 		ProjectKey: p.ProjectKey,
 		Namespace:  "",
 		RepoSlug:   p.RepoSlug,
 		MainBranch: p.MainBranch,
-		PathToScan: fmt.Sprintf("%s://%s:%s@%sscm/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["Users"].(string), platformConfig["AccessToken"].(string), trimmedURL, p.ProjectKey, p.RepoSlug),
+		PathToScan: fmt.Sprintf("%s://%sscm/%s/%s.git", platformConfig["Protocol"].(string), trimmedURL, p.ProjectKey, p.RepoSlug),
+		Credential: gogit.Basic(platformConfig["Users"].(string), platformConfig["AccessToken"].(string)),
 	}
-	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+	options := getAnalysisOptions(platformConfig)
+	options.useSSH(&params, p.SSHURL)
+	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), options)
 }
 
 // Analysis functions for GitHub
@@ -465,16 +644,17 @@ func analyseGithubRepo(project interface{}, DestinationResult string, platformCo
 	var excludeExtensions []string
 
 	excludeExtensions = convertToSliceString(platformConfig["ExtExclusion"].([]interface{}))
-	excludePath := getExcludePaths(platformConfig["ExcludePaths"])
+	excludePath := getStringList(platformConfig["ExcludePaths"])
 
 	params := RepoParams{
 		ProjectKey: p.Org,
 		Namespace:  "",
 		RepoSlug:   p.RepoSlug,
 		MainBranch: p.MainBranch,
-		PathToScan: fmt.Sprintf("%s://%s:x-oauth-basic@%s/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["AccessToken"].(string), platformConfig["Baseapi"].(string), p.Org, p.RepoSlug),
+		PathToScan: fmt.Sprintf("%s://%s/%s/%s.git", platformConfig["Protocol"].(string), platformConfig["Baseapi"].(string), p.Org, p.RepoSlug),
+		Credential: gogit.Token("x-access-token", platformConfig["AccessToken"].(string)),
 	}
-	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), getAnalysisOptions(platformConfig))
 }
 
 // Analysis functions for GitLab
@@ -483,7 +663,7 @@ func analyseGitlabRepo(project interface{}, DestinationResult string, platformCo
 	var excludeExtensions []string
 
 	excludeExtensions = convertToSliceString(platformConfig["ExtExclusion"].([]interface{}))
-	excludePath := getExcludePaths(platformConfig["ExcludePaths"])
+	excludePath := getStringList(platformConfig["ExcludePaths"])
 
 	domain := extractDomain(platformConfig["Url"].(string))
 
@@ -492,9 +672,12 @@ func analyseGitlabRepo(project interface{}, DestinationResult string, platformCo
 		Namespace:  p.Namespace,
 		RepoSlug:   p.RepoSlug,
 		MainBranch: p.MainBranch,
-		PathToScan: fmt.Sprintf("%s://gitlab-ci-token:%s@%s/%s.git", platformConfig["Protocol"].(string), platformConfig["AccessToken"].(string), domain, p.Namespace),
+		PathToScan: fmt.Sprintf("%s://%s/%s.git", platformConfig["Protocol"].(string), domain, p.Namespace),
+		Credential: gogit.Token("gitlab-ci-token", platformConfig["AccessToken"].(string)),
 	}
-	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+	options := getAnalysisOptions(platformConfig)
+	options.useSSH(&params, p.SSHURL)
+	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), options)
 }
 
 func analyseAzurebRepo(project interface{}, DestinationResult string, platformConfig map[string]interface{}, spin *spinner.Spinner, results chan int, count *int) {
@@ -502,20 +685,21 @@ func analyseAzurebRepo(project interface{}, DestinationResult string, platformCo
 	var excludeExtensions []string
 
 	excludeExtensions = convertToSliceString(platformConfig["ExtExclusion"].([]interface{}))
-	excludePath := getExcludePaths(platformConfig["ExcludePaths"])
+	excludePath := getStringList(platformConfig["ExcludePaths"])
 
 	params := RepoParams{
 		ProjectKey: p.ProjectKey,
 		Namespace:  "",
 		RepoSlug:   p.RepoSlug,
 		MainBranch: p.MainBranch,
-		PathToScan: fmt.Sprintf("%s://%s@%s/%s/%s/%s/%s", platformConfig["Protocol"].(string), platformConfig["AccessToken"].(string), "dev.azure.com", platformConfig["Organization"].(string), p.ProjectKey, "_git", p.RepoSlug),
+		PathToScan: fmt.Sprintf("%s://%s/%s/%s/%s/%s", platformConfig["Protocol"].(string), "dev.azure.com", platformConfig["Organization"].(string), p.ProjectKey, "_git", p.RepoSlug),
+		Credential: gogit.Token("", platformConfig["AccessToken"].(string)),
 	}
-	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+	performRepoAnalysis(params, DestinationResult, spin, results, count, excludeExtensions, excludePath, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), getAnalysisOptions(platformConfig))
 }
 
 // Perform repository analysis (common logic)
-func performRepoAnalysis(params RepoParams, DestinationResult string, spin *spinner.Spinner, results chan int, count *int, excludeExtension []string, excludePaths []string, ResultByFile bool, ResultAll bool) {
+func performRepoAnalysis(params RepoParams, DestinationResult string, spin *spinner.Spinner, results chan int, count *int, excludeExtension []string, excludePaths []string, ResultByFile bool, ResultAll bool, options AnalysisOptions) {
 	// Always use a consistent filename pattern so downstream parsing works across platforms
 	// Format: Result_<OrgOrProjectKey>_<RepoSlug>_<Branch>
 	outputFileName := fmt.Sprintf("Result_%s_%s_%s", params.ProjectKey, params.RepoSlug, params.MainBranch)
@@ -538,17 +722,31 @@ func performRepoAnalysis(params RepoParams, DestinationResult string, spin *spin
 		OutputPath:        DestinationResult,
 		ReportFormats:     []string{"json"},
 		Branch:            params.MainBranch,
+		Credential:        params.Credential,
 		Cloned:            false,
 		Repopath:          "",
 	}
-	if ResultAll {
-		golocParams.ByFile = true
+	options.apply(&golocParams)
+	options.applyRepoPaths(&golocParams, params.ProjectKey+"/"+params.RepoSlug, params.Namespace, params.RepoSlug)
+	golocParams.Cache = repoCache
+	golocParams.CacheKey = gogit.CacheKey{Platform: options.Platform, Repository: params.ProjectKey + "/" + params.RepoSlug}
+
+	var configHash string
+	if resultCache != nil {
+		var restored bool
+		if restored, configHash = restoreResults(golocParams); restored {
+			registerResultBranch(outputFileName, utils.ResultBranch{Project: params.ProjectKey, Repository: params.RepoSlug, Branch: params.MainBranch})
+			cachedResults.Add(1)
+			logger.Infof("\r\t\t\t\t✅ %d The repository <%s> is unchanged, its results are served from the cache\n", *count, params.RepoSlug)
+			results <- 1
+			return
+		}
 	}
 	MessB := fmt.Sprintf("   Extracting files from repo : %s ", params.RepoSlug)
 	spin.Suffix = MessB
 	spin.Start()
 
-	gc, err := goloc.NewGCloc(golocParams, assets.Languages)
+	gc, err := goloc.NewGCloc(golocParams, AppLanguages)
 	if err != nil {
 		logger.Errorf(errorMessageRepo+"%v", err)
 		*count++
@@ -556,53 +754,28 @@ func performRepoAnalysis(params RepoParams, DestinationResult string, spin *spin
 		return
 	} else {
 
-		//gc.Run()
-		//*count++
-
-		if ResultAll {
-
-			if err := gc.Run(); err != nil {
-				fmt.Print("\n")
-				logger.Errorf("❌ Error during analysis with ByAll = true: %v", err)
-				*count++
-				results <- 1
-				return
+		// Run writes the reports selected by ByFile, or both the by-file and the
+		// by-language reports when ResultAll sets ByAll
+		if err := gc.Run(); err != nil {
+			fmt.Print("\n")
+			logger.Errorf("❌ Error during analysis: %v", err)
+			if err := gc.RemoveRepo(); err != nil {
+				logger.Errorf(errorMessageDi, err)
 			}
+			*count++
+			results <- 1
+			return
+		}
 
-			// Second call to Run with ByFile = false
-			golocParams.ByFile = false
-			golocParams.Cloned = true
-			golocParams.Repopath = gc.Repopath
-
-			gc, err = goloc.NewGCloc(golocParams, assets.Languages)
-			if err != nil {
-				fmt.Print("\n")
-				logger.Errorf("❌ Error initializing GCloc for ByFile = false: %v", err)
-				*count++
-				results <- 1
-				return
-			}
-
-			if err := gc.Run(); err != nil {
-				fmt.Print("\n")
-				logger.Errorf("❌ Error during analysis with ByFile = false: %v", err)
-				*count++
-				results <- 1
-				return
-			}
-		} else {
-			// If ByAll = false, just run normally
-			if err := gc.Run(); err != nil {
-				fmt.Print("\n")
-				logger.Errorf("❌ Error during analysis: %v", err)
-				*count++
-				results <- 1
-				return
+		registerResultBranch(outputFileName, utils.ResultBranch{Project: params.ProjectKey, Repository: params.RepoSlug, Branch: params.MainBranch})
+		if configHash != "" {
+			if err := resultCache.Store(golocParams, gc.Commit, configHash); err != nil {
+				logger.Errorf("%v", err)
 			}
 		}
 
-		// Remove Repository Directory
-		err1 := os.RemoveAll(gc.Repopath)
+		// Remove Repository Directory, or give it back to the cache
+		err1 := gc.RemoveRepo()
 		if err1 != nil {
 			logger.Errorf(errorMessageDi, err1)
 		}
@@ -614,6 +787,30 @@ func performRepoAnalysis(params RepoParams, DestinationResult string, spin *spin
 	}
 }
 
+// restoreResults restores the reports of the branch from the result cache
+// when its last commit, asked to the platform, was already analyzed with
+// the same configuration. Otherwise it returns the configuration hash the
+// new reports are kept with, empty when they cannot be.
+func restoreResults(params goloc.Params) (bool, string) {
+	configHash, err := goloc.ConfigHash(version1, params, AppLanguages)
+	if err != nil {
+		logger.Warnf("❗️ Result cache not used for %s: %v", params.OutputName, err)
+		return false, ""
+	}
+	commit, err := gogit.RemoteCommit(params.Path, params.Branch, params.Credential)
+	if err != nil {
+		logger.Warnf("❗️ Result cache not used for %s: %v", params.OutputName, err)
+		return false, configHash
+	}
+	restored, err := resultCache.Restore(params, commit, configHash)
+	if err != nil {
+		logger.Warnf("❗️ %v", err)
+		return false, configHash
+	}
+
+	return restored, configHash
+}
+
 // Wait for all goroutines to complete
 func waitForWorkers(numWorkers int, results chan int) {
 	for i := 0; i < numWorkers; i++ {
@@ -675,7 +872,7 @@ func AnalyseReposListAzure(DestinationResult string, platformConfig map[string]i
 
 /* ---------------- Analyse Directory ---------------- */
 
-func AnalyseReposListFile(Listdirectorie, fileexclusionEX []string, extexclusion []string, ResultByFile bool, ResultAll bool) {
+func AnalyseReposListFile(Listdirectorie, fileexclusionEX []string, extexclusion []string, ResultByFile bool, ResultAll bool, options AnalysisOptions) {
 
 	type Configuration struct {
 		ExcludeExtensions []string
@@ -719,52 +916,26 @@ func AnalyseReposListFile(Listdirectorie, fileexclusionEX []string, extexclusion
 				OutputPath:        "Results",
 				ReportFormats:     []string{"json"},
 				Branch:            "",
-				Token:             "",
 				Cloned:            false,
 				Repopath:          "",
 			}
+			options.apply(&params)
+			options.applyRepoPaths(&params, dir, filepath.Base(dir))
 
-			gc, err := goloc.NewGCloc(params, assets.Languages)
+			gc, err := goloc.NewGCloc(params, AppLanguages)
 			if err != nil {
 				logger.Errorf(errorMessageRepo+"%v", err)
 				return
 			} else {
 
-				if ResultAll {
-
-					if err := gc.Run(); err != nil {
-						fmt.Print("\n")
-						logger.Errorf("❌ Error during analysis with ByAll = true: %v", err)
-
-						return
-					}
-
-					// Second call to Run with ByFile = false
-					params.ByFile = true
-
-					params.Cloned = false
-					params.Repopath = gc.Repopath
-
-					gc, err = goloc.NewGCloc(params, assets.Languages)
-					if err != nil {
-						fmt.Print("\n")
-						logger.Errorf("❌ Error initializing GCloc for ByFile = false: %v", err)
-						return
-					}
-
-					if err := gc.Run(); err != nil {
-						fmt.Print("\n")
-						logger.Errorf("❌ Error during analysis with ByFile = false: %v", err)
-						return
-					}
-				} else {
-					// If ByAll = false, just run normally
-					if err := gc.Run(); err != nil {
-						fmt.Print("\n")
-						logger.Errorf("❌ Error during analysis: %v", err)
-						return
-					}
+				// Run writes the reports selected by ByFile, or both the by-file and the
+				// by-language reports when ResultAll sets ByAll
+				if err := gc.Run(); err != nil {
+					fmt.Print("\n")
+					logger.Errorf("❌ Error during analysis: %v", err)
+					return
 				}
+				registerResultBranch(gc.Params.OutputName, utils.ResultBranch{Repository: filepath.Base(dir)})
 
 			}
 
@@ -782,7 +953,7 @@ func AnalyseReposListFile(Listdirectorie, fileexclusionEX []string, extexclusion
 /* ---------------- End Analyse Directory ---------------- */
 
 func AnalyseRun(params goloc.Params, reponame string) {
-	gc, err := goloc.NewGCloc(params, assets.Languages)
+	gc, err := goloc.NewGCloc(params, AppLanguages)
 	if err != nil {
 		fmt.Println(errorMessageRepo, err)
 		os.Exit(1)
@@ -794,7 +965,7 @@ func AnalyseRun(params goloc.Params, reponame string) {
 func AnalyseRepo(DestinationResult string, Users string, AccessToken string, DevOps string, Organization string, reponame string) (cpt int) {
 
 	//pathToScan := fmt.Sprintf("git::https://%s@%s.com/%s/%s", AccessToken, DevOps, Organization, reponame)
-	pathToScan := fmt.Sprintf("https://%s:%s@%s.com/%s/%s", Users, AccessToken, DevOps, Organization, reponame)
+	pathToScan := fmt.Sprintf("https://%s.com/%s/%s", DevOps, Organization, reponame)
 
 	outputFileName := fmt.Sprintf("Result_%s", reponame)
 	params := goloc.Params{
@@ -815,11 +986,11 @@ func AnalyseRepo(DestinationResult string, Users string, AccessToken string, Dev
 		OutputPath:        DestinationResult,
 		ReportFormats:     []string{"json"},
 		Branch:            "",
-		Token:             "",
+		Credential:        gogit.Basic(Users, AccessToken),
 		Cloned:            true,
 		Repopath:          "",
 	}
-	gc, err := goloc.NewGCloc(params, assets.Languages)
+	gc, err := goloc.NewGCloc(params, AppLanguages)
 	if err != nil {
 		fmt.Println(errorMessageRepo, err)
 		os.Exit(1)
@@ -862,12 +1033,116 @@ func ReadLines(filename string) ([]string, error) {
 	return lines, nil
 }
 
+// Load the built-in languages merged with the definitions of the languages
+// file, then with the ones of the config file
+func loadLanguages(config Config, configPath string) (language.Languages, error) {
+	languages := assets.Languages
+	configDir := filepath.Dir(configPath)
+
+	languagesFile := config.LanguagesFile
+	if languagesFile == "" {
+		for _, name := range languagesFiles {
+			if _, err := os.Stat(filepath.Join(configDir, name)); err == nil {
+				languagesFile = name
+				break
+			}
+		}
+	}
+
+	if languagesFile != "" {
+		path := languagesFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(configDir, path)
+		}
+		definitions, err := language.ReadDefinitions(path)
+		if err != nil {
+			return nil, err
+		}
+		if languages, err = languages.Merge(definitions, languagesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return languages.Merge(config.Languages, filepath.Base(configPath))
+}
+
+// resultTotal holds the lines of code of a result file counted in the totals
+type resultTotal struct {
+	codeLines     int
+	testCodeLines int
+	byEdition     map[string]int
+}
+
+// Exclude JSON LOC from total to match SonarQube standard behavior
+func newResultTotal(result Result) resultTotal {
+	total := resultTotal{
+		codeLines:     result.TotalCodeLines,
+		testCodeLines: result.TotalTestCodeLines,
+		byEdition:     map[string]int{},
+	}
+	for _, r := range result.Results {
+		if utils.IsExcludedFromTotalLOC(r.Language) {
+			total.codeLines -= r.CodeLines
+			total.testCodeLines -= r.TestCodeLines
+			continue
+		}
+		total.byEdition[editionOf(r.Language)] += r.CodeLines
+	}
+
+	return total
+}
+
+// Record the repository and the branch a result file was produced from
+func registerResultBranch(name string, branch utils.ResultBranch) {
+	resultBranchesMu.Lock()
+	defer resultBranchesMu.Unlock()
+	resultBranches[name] = branch
+}
+
+// Get the first SonarQube edition analyzing the language
+func editionOf(lang string) string {
+	// Notebooks are analyzed with the language of their kernel
+	lang = strings.TrimSuffix(strings.TrimSpace(lang), language.NotebookSuffix)
+	if edition := AppLanguages[lang].Edition; edition != "" {
+		return edition
+	}
+
+	return utils.EditionNotAnalyzed
+}
+
+// Get the edition of each language for the license report
+func languageEditions() map[string]string {
+	editions := make(map[string]string, len(AppLanguages))
+	for lang, info := range AppLanguages {
+		editions[lang] = info.Edition
+		editions[lang+language.NotebookSuffix] = info.Edition
+	}
+
+	return editions
+}
+
+func formatCodeLinesByEdition(codeLines map[string]int) map[string]string {
+	formatted := make(map[string]string, len(codeLines))
+	for edition, lines := range codeLines {
+		formatted[edition] = utils.FormatCodeLines(float64(lines))
+	}
+
+	return formatted
+}
+
 func displayLanguages() {
-	fmt.Printf("%-18s | %-78s | %-15s | %s\n", "Language", "Extensions", "Single Comments", "Multi Line Comments")
-	fmt.Println("-------------------+--------------------------------------------------------------------------------+-----------------+--------------------")
+	fmt.Printf("%-18s | %-78s | %-15s | %-20s | %-10s | %s\n", "Language", "Extensions", "Single Comments", "Multi Line Comments", "Edition", "Origin")
+	fmt.Println("-------------------+--------------------------------------------------------------------------------+-----------------+----------------------+------------+----------------")
 
-	for lang, config := range assets.Languages {
-		extensions := strings.Join(config.Extensions, ", ") // Concatenate extensions with comma separator
+	names := make([]string, 0, len(AppLanguages))
+	for lang := range AppLanguages {
+		names = append(names, lang)
+	}
+	sort.Strings(names)
+
+	for _, lang := range names {
+		config := AppLanguages[lang]
+		extensions := strings.Join(append(append([]string{}, config.Extensions...), config.FileNames...), ", ") // Concatenate extensions and file names with comma separator
 
 		singleComments := strings.Join(config.LineComments, ", ") // Concatenate single comments with comma separator
 
@@ -878,7 +1153,7 @@ func displayLanguages() {
 			}
 		}
 
-		fmt.Printf("%-18s | %-78s | %-15s | %s\n", lang, extensions, singleComments, multiLineComments)
+		fmt.Printf("%-18s | %-78s | %-15s | %-20s | %-10s | %s\n", lang, extensions, singleComments, multiLineComments, editionOf(lang), config.Origin)
 	}
 }
 
@@ -912,6 +1187,13 @@ func init() {
 
 	logrus.Info("✅ Configuration loaded successfully and version matched!")
 
+	AppLanguages, err = loadLanguages(AppConfig, configPath)
+	if err != nil {
+		logrus.Fatalf("\n❌ Failed to load languages: %s", err)
+		os.Exit(1)
+	}
+	utils.SetLanguagesExcludedFromTotalLOC(AppConfig.License.ExcludedLanguages)
+
 	// Create Logs Directory
 	logDir := "Logs"
 	if _, err := os.Stat(logDir); os.IsNotExist(err) {
@@ -940,6 +1222,10 @@ type ApplicationFlags struct {
 	Help        bool
 	Languages   bool
 	Version     bool
+	// CacheDir keeps the clones of the repositories across runs, up to
+	// CacheMaxSizeMB when positive.
+	CacheDir       string
+	CacheMaxSizeMB int64
 }
 
 // parseAndValidateFlags processes command line arguments and validates them
@@ -951,6 +1237,9 @@ func parseAndValidateFlags() (ApplicationFlags, map[string]interface{}) {
 	helpFlag := flag.Bool("help", false, "Show help message")
 	languagesFlag := flag.Bool("languages", false, "Show all supported languages")
 	versionflag := flag.Bool("version", false, "Show version")
+	cacheDirFlag := flag.String("cache-dir", "", "Keep the clones of the repositories in this directory across runs")
+	cacheMaxSizeFlag := flag.Int64("cache-max-size", 10240, "Size in MB of the clone cache, above which the least recently used clones are evicted (0: no limit)")
+	pruneCacheFlag := flag.Bool("prune-cache", false, "Evict the least recently used clones of -cache-dir down to -cache-max-size (all of them with 0) and exit")
 
 	flag.Parse()
 
@@ -963,6 +1252,8 @@ func parseAndValidateFlags() (ApplicationFlags, map[string]interface{}) {
 		fmt.Println("  golc -devops Github                    # Analyze main branches only")
 		fmt.Println("  golc -devops Github -all-branches      # Analyze ALL branches")
 		fmt.Println("  golc -devops Github -fast              # Fast analysis mode")
+		fmt.Println("  golc -devops Github -cache-dir cache   # Keep the clones across runs")
+		fmt.Println("  golc -prune-cache -cache-dir cache     # Prune the clone cache")
 		flag.PrintDefaults()
 		os.Exit(0)
 	}
@@ -977,6 +1268,14 @@ func parseAndValidateFlags() (ApplicationFlags, map[string]interface{}) {
 		os.Exit(0)
 	}
 
+	if *pruneCacheFlag {
+		if err := pruneCache(*cacheDirFlag, *cacheMaxSizeFlag); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Validate required flags
 	if *devopsFlag == "" {
 		fmt.Println("\n❌ Please specify the DevOps platform using the -devops flag : <BitBucketSRV>||<BitBucket>||<Github>||<GithubEnterprise>||<Gitlab>||<Azure>||<File>")
@@ -992,15 +1291,43 @@ func parseAndValidateFlags() (ApplicationFlags, map[string]interface{}) {
 	}
 
 	return ApplicationFlags{
-		DevOps:      *devopsFlag,
-		Fast:        *fastFlag,
-		AllBranches: *allBranchesFlag,
-		Help:        *helpFlag,
-		Languages:   *languagesFlag,
-		Version:     *versionflag,
+		DevOps:         *devopsFlag,
+		Fast:           *fastFlag,
+		AllBranches:    *allBranchesFlag,
+		Help:           *helpFlag,
+		Languages:      *languagesFlag,
+		Version:        *versionflag,
+		CacheDir:       *cacheDirFlag,
+		CacheMaxSizeMB: *cacheMaxSizeFlag,
 	}, platformConfig
 }
 
+// pruneCache evicts the least recently used clones of the cache in dir until
+// it is no larger than maxSizeMB, and empties its result cache with 0
+func pruneCache(dir string, maxSizeMB int64) error {
+	if dir == "" {
+		return fmt.Errorf("❌ Please specify the clone cache using the -cache-dir flag")
+	}
+	cache, err := gogit.NewCache(filepath.Join(dir, cloneCacheDir), maxSizeMB*1024*1024)
+	if err != nil {
+		return err
+	}
+	evicted, err := cache.Prune(cache.MaxSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %d clones evicted from %s, %.1f MB left\n", len(evicted), dir, float64(cache.Size())/(1024*1024))
+
+	if maxSizeMB == 0 {
+		if err := os.RemoveAll(filepath.Join(dir, resultCacheDir)); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Result cache of %s emptied\n", dir)
+	}
+
+	return nil
+}
+
 // setupResultsDirectory handles Results directory creation and backup logic
 func setupResultsDirectory(flags ApplicationFlags) string {
 	pwd, err := os.Getwd()
@@ -1062,6 +1389,17 @@ func main() {
 	// Parse and validate command line flags
 	flags, platformConfig := parseAndValidateFlags()
 
+	if flags.CacheDir != "" {
+		cache, err := gogit.NewCache(filepath.Join(flags.CacheDir, cloneCacheDir), flags.CacheMaxSizeMB*1024*1024)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return
+		}
+		repoCache = cache
+		resultCache = &goloc.ResultCache{Dir: filepath.Join(flags.CacheDir, resultCacheDir)}
+		logger.Infof("✅ Using clone cache '%s' (%.1f MB)\n", flags.CacheDir, float64(cache.Size())/(1024*1024))
+	}
+
 	// Setup results directory
 	DestinationResult := setupResultsDirectory(flags)
 	fmt.Printf("\n")
@@ -1269,11 +1607,16 @@ func main() {
 			}
 		}
 		startTime = time.Now()
-		AnalyseReposListFile(ListDirectory, ListExclusion, excludeExtensions, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool))
+		AnalyseReposListFile(ListDirectory, ListExclusion, excludeExtensions, platformConfig["ResultByFile"].(bool), platformConfig["ResultAll"].(bool), getAnalysisOptions(platformConfig))
 	}
 
 	/*---------------------------------- End Select type of DevOps Platform ----------------------------------------------------*/
 
+	// The license report reads the branch of each result file back
+	if err := utils.SaveResultBranches(resultBranches); err != nil {
+		logger.Errorf("❌ Error saving result branches: %v", err)
+	}
+
 	// Begin of report file analysis
 	//fmt.Print("\n🔎 Analyse Report ...\n")
 
@@ -1302,8 +1645,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize the sum of TotalCodeLines (excluding JSON to match SonarQube behavior)
-	totalCodeLinesSum := 0
+	// Totals of each result file, excluding JSON to match SonarQube behavior
+	resultTotals := map[string]resultTotal{}
+	resultCodeLines := map[string]int{}
 
 	// Analyse All file
 	for _, file := range files {
@@ -1325,35 +1669,42 @@ func main() {
 				continue
 			}
 
-			// Exclude JSON LOC from total to match SonarQube standard behavior
-			jsonLOC := 0
-			for _, r := range result.Results {
-				if strings.TrimSpace(r.Language) == utils.LanguageExcludedFromTotalLOC {
-					jsonLOC += r.CodeLines
-					break
-				}
-			}
-			codeLinesForTotal := result.TotalCodeLines - jsonLOC
-
-			totalCodeLinesSum += codeLinesForTotal
-
-			// Check if this repo has a higher TotalCodeLines (excl. JSON) than the current maximum
-			if codeLinesForTotal > maxTotalCodeLines {
-				maxTotalCodeLines = codeLinesForTotal
-				// Extract project and repo name from file name
-				parts := strings.Split(strings.TrimSuffix(file.Name(), ".json"), "_")
-				if platformConfig["DevOps"].(string) != "file" {
-					maxProject = parts[1]
-					maxRepo = parts[2]
-				} else {
-					maxProject = ""
-					maxRepo = parts[1]
-					NumberRepos++
-				}
-			}
+			name := strings.TrimSuffix(file.Name(), ".json")
+			total := newResultTotal(result)
+			resultTotals[name] = total
+			resultCodeLines[name] = total.codeLines
 		}
 
 	}
+
+	// Each repository counts once, with its largest branch, like SonarQube licensing does
+	branchReport := utils.LargestBranches(resultCodeLines, resultBranches)
+	if err := utils.WriteBranchReport(branchReport); err != nil {
+		logger.Errorf("❌ Error creating branch report: %v", err)
+	}
+
+	totalCodeLinesSum := 0
+	totalTestCodeLinesSum := 0
+	codeLinesByEdition := map[string]int{}
+	for _, name := range branchReport.CountedResults() {
+		total := resultTotals[name]
+		totalCodeLinesSum += total.codeLines
+		totalTestCodeLinesSum += total.testCodeLines
+		for edition, lines := range total.byEdition {
+			codeLinesByEdition[edition] += lines
+		}
+	}
+
+	// Repositories are sorted by decreasing lines of code
+	if len(branchReport.Repositories) > 0 {
+		largest := branchReport.Repositories[0]
+		maxTotalCodeLines = largest.CodeLines
+		maxProject = largest.Project
+		maxRepo = largest.Repository
+	}
+	if platformConfig["DevOps"].(string) == "file" || flags.AllBranches {
+		NumberRepos = len(branchReport.Repositories)
+	}
 	maxTotalCodeLines1 := utils.FormatCodeLines(float64(maxTotalCodeLines))
 	totalCodeLinesSum1 := utils.FormatCodeLines(float64(totalCodeLinesSum))
 
@@ -1369,10 +1720,14 @@ func main() {
 	data := OrganizationData{
 		Organization:           platformConfig["Organization"].(string),
 		TotalLinesOfCode:       totalCodeLinesSum1,
+		MainLinesOfCode:        utils.FormatCodeLines(float64(totalCodeLinesSum - totalTestCodeLinesSum)),
+		TestLinesOfCode:        utils.FormatCodeLines(float64(totalTestCodeLinesSum)),
 		LargestRepository:      maxRepo,
 		LinesOfCodeLargestRepo: maxTotalCodeLines1,
 		DevOpsPlatform:         platformConfig["DevOps"].(string),
 		NumberRepos:            NumberRepos,
+		LinesOfCodeByEdition:   formatCodeLinesByEdition(codeLinesByEdition),
+		CachedRepos:            cachedResults.Load(),
 	}
 
 	jsonData, err := json.MarshalIndent(data, "", "    ")
@@ -1398,6 +1753,14 @@ func main() {
 	// Determine base Results directory (parent of current DestinationResult)
 	baseResultsDir := filepath.Dir(filepath.Clean(DestinationResult))
 
+	// Generated License Report before the Global Report, which shows it
+	license, err := utils.CreateLicenseReport(baseResultsDir, languageEditions(), AppConfig.License.Tiers)
+	if err != nil {
+		logger.Errorf("❌ Error creating license report: %v", err)
+	} else if license.RecommendedTier != nil {
+		logger.Infof("✅ Licensed lines of code : %s - Recommended license : %s", license.LicensedLinesOfCodeF, license.RecommendedTier)
+	}
+
 	// Generated Global Report (walks the directory for Result_* files)
 	// Pass the base Results directory for consistency across platforms.
 	err = utils.CreateGlobalReport(baseResultsDir)
@@ -1446,6 +1809,11 @@ func main() {
 
 	logger.Info(message0)
 	logger.Info(message2)
+	if resultCache != nil {
+		message6 := fmt.Sprintf("✅ %d repositories served from the result cache\n", cachedResults.Load())
+		message5 = message3 + message6 + message4
+		logger.Info(message6)
+	}
 	logger.Infof("✅ Reports are located in the <'Results'> directory")
 	logger.Info(message4)
 
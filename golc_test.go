@@ -15,6 +15,8 @@ import (
 	getbibucketdc "github.com/SonarSource-Demos/sonar-golc/pkg/devops/getbitbucketdc"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/devops/getgithub"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/devops/getgitlab"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/gogit"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc"
 )
 
 // Constants to avoid duplicating string literals (SonarQube maintainability)
@@ -102,25 +104,119 @@ func TestUtilityFunctions(t *testing.T) {
 		}
 	})
 
-	t.Run("getExcludePaths function", func(t *testing.T) {
+	t.Run("getStringList function", func(t *testing.T) {
 		// Test with nil
-		result := getExcludePaths(nil)
+		result := getStringList(nil)
 		if len(result) != 0 {
-			t.Error("getExcludePaths should return empty slice for nil input")
+			t.Error("getStringList should return empty slice for nil input")
 		}
 
 		// Test with valid slice
 		input := []interface{}{"path1", "path2", "path3"}
-		result = getExcludePaths(input)
+		result = getStringList(input)
 		if len(result) != 3 {
-			t.Errorf("getExcludePaths should return slice of length 3, got: %d", len(result))
+			t.Errorf("getStringList should return slice of length 3, got: %d", len(result))
 		}
 
 		// Test with invalid type
 		invalidInput := "not a slice"
-		result = getExcludePaths(invalidInput)
+		result = getStringList(invalidInput)
 		if len(result) != 0 {
-			t.Error("getExcludePaths should return empty slice for invalid input")
+			t.Error("getStringList should return empty slice for invalid input")
+		}
+	})
+
+	t.Run("applyRepoPaths function", func(t *testing.T) {
+		options := getAnalysisOptions(map[string]interface{}{
+			"IncludePaths": []interface{}{"src/**"},
+			"RepoPaths": map[string]interface{}{
+				"org/api": map[string]interface{}{"ExcludePaths": []interface{}{"!src/gen/keep.go"}},
+				"web":     map[string]interface{}{"IncludePaths": []interface{}{"app/**"}},
+			},
+		})
+
+		params := goloc.Params{ExcludePaths: []string{"src/gen"}}
+		options.apply(&params)
+		options.applyRepoPaths(&params, "org/api", "api")
+		if len(params.IncludePaths) != 1 || len(params.ExcludePaths) != 2 || params.ExcludePaths[1] != "!src/gen/keep.go" {
+			t.Errorf("unexpected paths for org/api: include %v, exclude %v", params.IncludePaths, params.ExcludePaths)
+		}
+
+		params = goloc.Params{}
+		options.apply(&params)
+		options.applyRepoPaths(&params, "org/web", "web")
+		if len(params.IncludePaths) != 2 || params.IncludePaths[1] != "app/**" {
+			t.Errorf("unexpected include paths for web: %v", params.IncludePaths)
+		}
+		if len(options.IncludePaths) != 1 {
+			t.Errorf("applyRepoPaths should not change the platform paths: %v", options.IncludePaths)
+		}
+	})
+
+	t.Run("useSSH function", func(t *testing.T) {
+		t.Setenv("GOLC_TEST_PASSPHRASE", "secret")
+		options := getAnalysisOptions(map[string]interface{}{
+			"SSHKeyPath":          "/keys/id_ed25519",
+			"SSHKeyPassphraseEnv": "GOLC_TEST_PASSPHRASE",
+			"SSHKnownHosts":       "/keys/known_hosts",
+		})
+		want := gogit.Credential{Kind: gogit.SSHKeyCredential, KeyPath: "/keys/id_ed25519", Secret: "secret", KnownHosts: "/keys/known_hosts"}
+		if options.SSH != want {
+			t.Errorf("unexpected SSH credential: %v", options.SSH)
+		}
+
+		params := RepoParams{RepoSlug: "repo", PathToScan: "https://gitlab.example.com/group/repo.git"}
+		options.useSSH(&params, "git@gitlab.example.com:group/repo.git")
+		if params.PathToScan != "git@gitlab.example.com:group/repo.git" || params.Credential != want {
+			t.Errorf("unexpected SSH clone: %+v", params)
+		}
+
+		params = RepoParams{RepoSlug: "repo", PathToScan: "https://gitlab.example.com/group/repo.git"}
+		options.useSSH(&params, "")
+		if params.PathToScan != "https://gitlab.example.com/group/repo.git" || params.Credential.IsSSH() {
+			t.Errorf("a repository without SSH link should be cloned over HTTPS: %+v", params)
+		}
+
+		if agent := getAnalysisOptions(map[string]interface{}{"SSHAgent": true}); agent.SSH.Kind != gogit.SSHAgentCredential {
+			t.Errorf("unexpected ssh-agent credential: %v", agent.SSH)
+		}
+		if none := getAnalysisOptions(map[string]interface{}{}); none.SSH.IsSSH() {
+			t.Errorf("SSH should be disabled by default: %v", none.SSH)
+		}
+	})
+
+	t.Run("pruneCache function", func(t *testing.T) {
+		if err := pruneCache("", 0); err == nil {
+			t.Error("pruneCache should need a cache directory")
+		}
+
+		dir := t.TempDir()
+		clone := filepath.Join(dir, cloneCacheDir, "github", "org_repo_main_0123abcd")
+		results := filepath.Join(dir, resultCacheDir, "Result_org_repo_main")
+		for _, path := range []string{clone, results} {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(clone, "main.go"), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := pruneCache(dir, 1); err != nil {
+			t.Fatalf("pruneCache error: %v", err)
+		}
+		if _, err := os.Stat(clone); err != nil {
+			t.Error("a clone within the size of the cache should be kept")
+		}
+		if _, err := os.Stat(results); err != nil {
+			t.Error("the results should be kept")
+		}
+		if err := pruneCache(dir, 0); err != nil {
+			t.Fatalf("pruneCache error: %v", err)
+		}
+		for _, path := range []string{clone, results} {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("pruneCache should empty the cache, found %s", path)
+			}
 		}
 	})
 }
@@ -590,7 +686,7 @@ func TestAnalysisListFunctions(t *testing.T) {
 					t.Errorf("AnalyseReposListFile panicked: %v", r)
 				}
 			}()
-			AnalyseReposListFile(emptyDirs, emptyExclusions, emptyExtensions, false, false)
+			AnalyseReposListFile(emptyDirs, emptyExclusions, emptyExtensions, false, false, AnalysisOptions{})
 		}()
 	})
 }
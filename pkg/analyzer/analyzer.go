@@ -3,21 +3,49 @@ package analyzer
 import (
 	"io/fs"
 	"path/filepath"
-	"strings"
 )
 
 type Analyzer struct {
 	SupportedExtensions map[string]string
-	path                string
-	excludePaths        []string
-	excludeExtensions   map[string]bool
-	includeExtensions   map[string]bool
+	// GitExclusions skips the files the repository marks as ignored in
+	// .gitignore, or as generated, vendored or documentation in .gitattributes.
+	GitExclusions bool
+	// FileNames maps file name patterns, such as "Dockerfile.*", to their
+	// language, and Shebangs maps the interpreters named on the "#!" line
+	// of the files without extension to theirs.
+	FileNames map[string]string
+	Shebangs  map[string]string
+	// ExtensionLanguages lists every language claiming an extension, for the
+	// heuristics choosing among them.
+	ExtensionLanguages map[string][]string
+	// TestPatterns lists, by language, the patterns of the files holding test code.
+	TestPatterns map[string][]string
+	// IncludePaths restricts the analysis to the files matching these path
+	// patterns, when one of them is not negated. See pathRules.
+	IncludePaths []string
+	// MaxFileSize skips the files larger than this many bytes, when positive.
+	MaxFileSize int64
+	// FS, when set, is the file source walked instead of the directory at
+	// path, such as the tree of a commit read without checkout. The files
+	// are then reported under path, which need not exist.
+	FS                fs.FS
+	path              string
+	excludePaths      []string
+	excludeExtensions map[string]bool
+	includeExtensions map[string]bool
+	excludedFiles     map[string]int
+	skippedFiles      []SkippedFile
+	dirExtensions     map[string]map[string]bool
 }
 
 type FileMetadata struct {
 	FilePath  string
 	Extension string
 	Language  string
+	Kind      string
+	// source and name tell where Open reads a file found in a file source.
+	source fs.FS
+	name   string
 }
 
 func NewAnalyzer(
@@ -38,32 +66,93 @@ func NewAnalyzer(
 
 func (a *Analyzer) MatchingFiles() ([]FileMetadata, error) {
 	var files []FileMetadata
+	var git *gitExclusions
+
+	a.excludedFiles = map[string]int{}
+	a.skippedFiles = nil
+	a.dirExtensions = map[string]map[string]bool{}
+	paths, err := newPathRules(a.IncludePaths, a.excludePaths)
+	if err != nil {
+		return nil, err
+	}
+	if a.GitExclusions {
+		settings, err := a.gitSettings()
+		if err != nil {
+			return nil, err
+		}
+		if git, err = loadGitExclusions(settings); err != nil {
+			return nil, err
+		}
+	}
 
-	err := filepath.Walk(a.path, func(path string, info fs.FileInfo, err error) error {
+	err = a.walk(func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(a.path, path)
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			// The files of the git metadata are never code, whatever
+			// GitExclusions is set to.
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if rel != "." && paths.skipsDir(rel) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
 		fileExtension := a.getFileExtension(path)
-		if a.canAdd(path, fileExtension) {
-			fm := FileMetadata{
-				FilePath:  path,
-				Extension: fileExtension,
-				Language:  a.SupportedExtensions[fileExtension],
+		if !paths.keeps(rel) || !a.canAdd(path) {
+			return nil
+		}
+
+		language := a.detectLanguage(path, fileExtension)
+		if language == "" && len(a.includeExtensions) == 0 {
+			return nil
+		}
+
+		if git != nil {
+			if reason := git.reason(rel); reason != "" {
+				a.excludedFiles[reason]++
+				return nil
 			}
-			files = append(files, fm)
 		}
 
+		if reason := a.skipReason(path, info); reason != "" {
+			a.skippedFiles = append(a.skippedFiles, SkippedFile{Path: path, Reason: reason})
+			return nil
+		}
+
+		file := a.metadata(path)
+		file.Extension = fileExtension
+		file.Language = language
+		file.Kind = kindOf(rel, a.TestPatterns[language])
+		files = append(files, file)
+
 		return nil
 	})
 
 	return files, err
 }
 
+// ExcludedFiles returns, by reason, the number of files the last call to
+// MatchingFiles left out because of the repository's own git settings.
+func (a *Analyzer) ExcludedFiles() map[string]int {
+	return a.excludedFiles
+}
+
+// SkippedFiles returns the files the last call to MatchingFiles left out
+// because they are too large or binary.
+func (a *Analyzer) SkippedFiles() []SkippedFile {
+	return a.skippedFiles
+}
+
 func (a *Analyzer) getFileExtension(path string) string {
 	extension := filepath.Ext(path)
 
@@ -74,22 +163,12 @@ func (a *Analyzer) getFileExtension(path string) string {
 	return extension
 }
 
-func (a *Analyzer) canAdd(path string, extension string) bool {
-	for _, pathToExclude := range a.excludePaths {
-		if strings.HasPrefix(path, pathToExclude) {
-			return false
-		}
-	}
-
+func (a *Analyzer) canAdd(path string) bool {
 	if len(a.includeExtensions) > 0 {
 		_, ok := a.includeExtensions[a.getFileExtension(path)]
 		return ok
 	}
 
-	if _, ok := a.excludeExtensions[a.getFileExtension(path)]; ok {
-		return false
-	}
-
-	_, ok := a.SupportedExtensions[extension]
-	return ok
+	_, ok := a.excludeExtensions[a.getFileExtension(path)]
+	return !ok
 }
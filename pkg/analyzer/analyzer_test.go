@@ -0,0 +1,445 @@
+package analyzer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// helper to create files (and their directories) under root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func relPaths(t *testing.T, root string, files []FileMetadata) []string {
+	t.Helper()
+	var paths []string
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f.FilePath)
+		if err != nil {
+			t.Fatalf("failed to get relative path: %v", err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestMatchingFilesGitExclusions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":            "build/\n",
+		".gitattributes":        "vendor/** linguist-vendored\n*.pb.go linguist-generated=true\ndocs/** linguist-documentation\n",
+		"main.go":               "package main\n",
+		"api.pb.go":             "package main\n",
+		"build/out.go":          "package build\n",
+		"vendor/lib/lib.go":     "package lib\n",
+		"vendor/keep/keep.go":   "package keep\n",
+		"vendor/.gitattributes": "keep/** -linguist-vendored\n",
+		"docs/example.go":       "package docs\n",
+		"sub/.gitignore":        "*.tmp.go\n",
+		"sub/code.go":           "package sub\n",
+		"sub/scratch.tmp.go":    "package sub\n",
+	})
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{".go": "Golang"})
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+	if len(files) != 8 || len(a.ExcludedFiles()) != 0 {
+		t.Errorf("expected every .go file without GitExclusions, got %v excluded=%v", relPaths(t, root, files), a.ExcludedFiles())
+	}
+
+	a.GitExclusions = true
+	files, err = a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+
+	got := relPaths(t, root, files)
+	want := []string{"main.go", "sub/code.go", "vendor/keep/keep.go"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+
+	excluded := a.ExcludedFiles()
+	expected := map[string]int{
+		ExcludedByGitignore:     2,
+		ExcludedAsVendored:      1,
+		ExcludedAsGenerated:     1,
+		ExcludedAsDocumentation: 1,
+	}
+	for reason, count := range expected {
+		if excluded[reason] != count {
+			t.Errorf("excluded[%s] = %d, want %d (all: %v)", reason, excluded[reason], count, excluded)
+		}
+	}
+}
+
+func TestMatchingFilesKind(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":                           "package main\n",
+		"main_test.go":                      "package main\n",
+		"src/main/java/App.java":            "class App {}\n",
+		"src/test/java/AppCheck.java":       "class AppCheck {}\n",
+		"src/main/java/AppTest.java":        "class AppTest {}\n",
+		"web/__tests__/app.js":              "test()\n",
+		"web/app.spec.ts":                   "test()\n",
+		"web/app.ts":                        "app()\n",
+		"e2e/login.ts":                      "login()\n",
+		"Service.Tests/ServiceChecks.cs":    "class ServiceChecks {}\n",
+		"Service/Service.cs":                "class Service {}\n",
+		"src/testing/java/Helper.java":      "class Helper {}\n",
+		"web/contest/unrelated_contest.js":  "run()\n",
+		"docs/fixtures/sample_fixture.java": "class Sample {}\n",
+	})
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{
+		".go": "Golang", ".java": "Java", ".js": "JavaScript", ".ts": "TypeScript", ".cs": "C#",
+	})
+	user := []string{"e2e/", "*_fixture.java"}
+	a.TestPatterns = map[string][]string{
+		"Golang":     append([]string{"*_test.go"}, user...),
+		"Java":       append([]string{"src/test/", "*Test.java"}, user...),
+		"JavaScript": append([]string{"__tests__/", "*.test.js"}, user...),
+		"TypeScript": append([]string{"__tests__/", "*.spec.ts"}, user...),
+		"C#":         append([]string{"*.Tests/"}, user...),
+	}
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+
+	var tests []FileMetadata
+	for _, f := range files {
+		if f.Kind != KindMain && f.Kind != KindTest {
+			t.Errorf("%s: unexpected kind %q", f.FilePath, f.Kind)
+		}
+		if f.Kind == KindTest {
+			tests = append(tests, f)
+		}
+	}
+
+	got := relPaths(t, root, tests)
+	want := []string{
+		"Service.Tests/ServiceChecks.cs",
+		"docs/fixtures/sample_fixture.java",
+		"e2e/login.ts",
+		"main_test.go",
+		"src/main/java/AppTest.java",
+		"src/test/java/AppCheck.java",
+		"web/__tests__/app.js",
+		"web/app.spec.ts",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("test files = %v, want %v", got, want)
+	}
+}
+
+func TestMatchingFilesDetectsFileNamesAndShebangs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"Dockerfile":           "FROM scratch\n",
+		"Dockerfile.prod":      "FROM scratch\n",
+		"build/app.dockerfile": "FROM scratch\n",
+		"Jenkinsfile":          "pipeline {}\n",
+		"Makefile":             "all:\n",
+		"Vagrantfile":          "Vagrant.configure\n",
+		"bin/tool":             "#!/usr/bin/env python3\nprint(1)\n",
+		"bin/run":              "#!/bin/bash\necho run\n",
+		"bin/split":            "#!/usr/bin/env -S ruby -w\nputs 1\n",
+		"bin/unknown":          "#!/usr/bin/perl\nprint 1;\n",
+		".git/hooks/pre-push":  "#!/bin/bash\nexit 0\n",
+		"README":               "no shebang\n",
+		"main.go":              "package main\n",
+	})
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{".go": "Golang", ".rb": "Ruby"})
+	a.FileNames = map[string]string{
+		"Dockerfile": "Docker", "Dockerfile.*": "Docker", "*.dockerfile": "Docker",
+		"Jenkinsfile": "Groovy", "Makefile": "Makefile", "Vagrantfile": "Ruby",
+	}
+	a.Shebangs = map[string]string{"python": "Python", "bash": "Shell", "ruby": "Ruby"}
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.FilePath)
+		got[filepath.ToSlash(rel)] = f.Language
+	}
+	want := map[string]string{
+		"Dockerfile":           "Docker",
+		"Dockerfile.prod":      "Docker",
+		"build/app.dockerfile": "Docker",
+		"Jenkinsfile":          "Groovy",
+		"Makefile":             "Makefile",
+		"Vagrantfile":          "Ruby",
+		"bin/tool":             "Python",
+		"bin/run":              "Shell",
+		"bin/split":            "Ruby",
+		"main.go":              "Golang",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for path, language := range want {
+		if got[path] != language {
+			t.Errorf("%s: language %q, want %q", path, got[path], language)
+		}
+	}
+}
+
+func TestMatchingFilesDisambiguatesSharedExtensions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"c/util.h":          "int add(int a, int b);\n",
+		"cpp/widget.h":      "namespace ui {\nclass Widget {\n};\n}\n",
+		"cpp/plain.h":       "int add(int a, int b);\n",
+		"cpp/widget.cpp":    "#include \"widget.h\"\n",
+		"objc/View.h":       "#import <UIKit/UIKit.h>\n@interface View : UIView\n@end\n",
+		"objc/View.m":       "#import \"View.h\"\n@implementation View\n@end\n",
+		"matlab/solve.m":    "function x = solve(a, b)\n% solve a linear system\nx = a \\ b;\n",
+		"apex/Account.cls":  "public with sharing class Account {}\n",
+		"vb/Account.cls":    "VERSION 1.0 CLASS\nAttribute VB_Name = \"Account\"\n",
+		"tex/article.cls":   "\\NeedsTeXFormat{LaTeX2e}\n\\ProvidesClass{article}\n",
+		"php/header.inc":    "<?php echo 'header'; ?>\n",
+		"pascal/consts.inc": "const Max = 10;\n",
+	})
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{
+		".h": "C Header", ".cpp": "C++", ".m": "Objective-C", ".cls": "Apex", ".inc": "PHP",
+	})
+	a.ExtensionLanguages = map[string][]string{
+		".h":   {"C Header", "C++ Header", "Objective-C"},
+		".cpp": {"C++"},
+		".m":   {"Objective-C"},
+		".cls": {"Apex", "VB6"},
+		".inc": {"PHP"},
+	}
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.FilePath)
+		got[filepath.ToSlash(rel)] = f.Language
+	}
+	want := map[string]string{
+		"c/util.h":         "C Header",
+		"cpp/widget.h":     "C++ Header",
+		"cpp/plain.h":      "C++ Header",
+		"cpp/widget.cpp":   "C++",
+		"objc/View.h":      "Objective-C",
+		"objc/View.m":      "Objective-C",
+		"apex/Account.cls": "Apex",
+		"vb/Account.cls":   "VB6",
+		"php/header.inc":   "PHP",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for path, language := range want {
+		if got[path] != language {
+			t.Errorf("%s: language %q, want %q", path, got[path], language)
+		}
+	}
+	for _, path := range []string{"matlab/solve.m", "tex/article.cls", "pascal/consts.inc"} {
+		if language, ok := got[path]; ok {
+			t.Errorf("%s: language %q, want it unclaimed", path, language)
+		}
+	}
+}
+
+func TestMatchingFilesPathPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.js":                        "",
+		"app.min.js":                     "",
+		"node_modules/lib/index.js":      "",
+		"web/node_modules/lib/index.js":  "",
+		"src/gen/model.js":               "",
+		"src/generator/run.js":           "",
+		"src/api/generated/client.js":    "",
+		"src/api/generated/keep/kept.js": "",
+		"src/api/handler.js":             "",
+		"docs/example.js":                "",
+	})
+	extensions := map[string]string{".js": "JavaScript"}
+
+	cases := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{
+			name:    "exclude globs",
+			exclude: []string{"**/node_modules/**", "**/*.min.js", "src/**/generated/*", "src/gen"},
+			want:    []string{"docs/example.js", "main.js", "src/api/handler.js", "src/generator/run.js"},
+		},
+		{
+			name:    "negation",
+			exclude: []string{"src/api", "!src/api/generated/keep/"},
+			want: []string{"app.min.js", "docs/example.js", "main.js", "node_modules/lib/index.js", "src/api/generated/keep/kept.js",
+				"src/gen/model.js", "src/generator/run.js", "web/node_modules/lib/index.js"},
+		},
+		{
+			name:    "include",
+			include: []string{"src/**", "!src/api/generated"},
+			exclude: []string{"src/gen"},
+			want:    []string{"src/api/handler.js", "src/generator/run.js"},
+		},
+	}
+
+	for _, c := range cases {
+		a := NewAnalyzer(root, c.exclude, map[string]bool{}, map[string]bool{}, extensions)
+		a.IncludePaths = c.include
+
+		files, err := a.MatchingFiles()
+		if err != nil {
+			t.Fatalf("%s: MatchingFiles: %v", c.name, err)
+		}
+		if got := relPaths(t, root, files); strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	a := NewAnalyzer(root, []string{"src/[a-"}, map[string]bool{}, map[string]bool{}, extensions)
+	if _, err := a.MatchingFiles(); err == nil {
+		t.Error("MatchingFiles should fail on an invalid path pattern")
+	}
+}
+
+func TestMatchingFilesSkipsLargeAndBinaryFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"small.sql":   "select 1;\n",
+		"dump.sql":    strings.Repeat("insert into t values (1);\n", 100),
+		"data.json":   "{\"a\": \x00\x01}",
+		"utf16.json":  "\xff\xfe{\x00}\x00",
+		"utf16be.sql": "\x00s\x00e\x00l\x00e\x00c\x00t\x00 \x001\x00;\x00\n",
+		"ignored.bin": "\x00",
+		"empty.json":  "",
+	})
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{".sql": "SQL", ".json": "JSON"})
+	a.MaxFileSize = 1024
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+	if got := relPaths(t, root, files); strings.Join(got, ",") != "empty.json,small.sql,utf16.json,utf16be.sql" {
+		t.Errorf("unexpected files: %v", got)
+	}
+
+	skipped := map[string]string{}
+	for _, file := range a.SkippedFiles() {
+		rel, _ := filepath.Rel(root, file.Path)
+		skipped[filepath.ToSlash(rel)] = file.Reason
+	}
+	if len(skipped) != 2 || skipped["dump.sql"] != SkippedTooLarge || skipped["data.json"] != SkippedBinary {
+		t.Errorf("unexpected skipped files: %v", skipped)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := map[string]string{
+		"package main\n":                  EncodingUTF8,
+		"\xef\xbb\xbfpackage main\n":      EncodingUTF8BOM,
+		"\xff\xfep\x00a\x00":              EncodingUTF16LE,
+		"\xfe\xff\x00p\x00a":              EncodingUTF16BE,
+		"v\x00a\x00r\x00 \x00x\x00\n\x00": EncodingUTF16LE,
+		"\x00v\x00a\x00r\x00 \x00x\x00\n": EncodingUTF16BE,
+		"{\"a\": \x00\x01}":               EncodingUTF8,
+		"":                                EncodingUTF8,
+	}
+	for head, want := range tests {
+		if got := DetectEncoding([]byte(head)); got != want {
+			t.Errorf("DetectEncoding(%q) = %s, want %s", head, got, want)
+		}
+	}
+}
+
+func TestMatchingFilesFromFileSource(t *testing.T) {
+	source := fstest.MapFS{
+		".gitignore":    {Data: []byte("build/\n")},
+		"main.go":       {Data: []byte("package main\n")},
+		"build/out.go":  {Data: []byte("package build\n")},
+		"cpp/widget.h":  {Data: []byte("int add(int a, int b);\n")},
+		"cpp/widget.cc": {Data: []byte("#include \"widget.h\"\n")},
+		"data.go":       {Data: []byte("\x00\x01")},
+		"run":           {Data: []byte("#!/usr/bin/env python3\nprint(1)\n")},
+	}
+	root := filepath.Join(t.TempDir(), "gcloc-extract-test")
+
+	a := NewAnalyzer(root, nil, map[string]bool{}, map[string]bool{}, map[string]string{".go": "Golang", ".h": "C Header", ".cc": "C++"})
+	a.FS = source
+	a.GitExclusions = true
+	a.Shebangs = map[string]string{"python": "Python"}
+	a.ExtensionLanguages = map[string][]string{".h": {"C Header", "C++ Header"}, ".cc": {"C++"}}
+
+	files, err := a.MatchingFiles()
+	if err != nil {
+		t.Fatalf("MatchingFiles: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.FilePath)
+		got[filepath.ToSlash(rel)] = f.Language
+
+		file, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open %s: %v", rel, err)
+		}
+		data, _ := io.ReadAll(file)
+		file.Close()
+		if string(data) != string(source[filepath.ToSlash(rel)].Data) {
+			t.Errorf("%s: read %q", rel, data)
+		}
+	}
+	want := map[string]string{"main.go": "Golang", "cpp/widget.h": "C++ Header", "cpp/widget.cc": "C++", "run": "Python"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if a.ExcludedFiles()[ExcludedByGitignore] != 1 {
+		t.Errorf("unexpected excluded files: %v", a.ExcludedFiles())
+	}
+	if skipped := a.SkippedFiles(); len(skipped) != 1 || skipped[0].Path != filepath.Join(root, "data.go") || skipped[0].Reason != SkippedBinary {
+		t.Errorf("unexpected skipped files: %+v", skipped)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("the analyzer path should not be created: %v", err)
+	}
+}
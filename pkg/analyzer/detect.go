@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"bufio"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxShebangLength bounds how much of a file is read to find its "#!" line.
+const maxShebangLength = 256
+
+// detectLanguage returns the language of the file from its name patterns,
+// then its extension, checked by heuristics when the extension is shared,
+// then the shebang of a file without extension, or an empty string when
+// none matches.
+func (a *Analyzer) detectLanguage(path, extension string) string {
+	if language := a.languageByFileName(filepath.Base(path)); language != "" {
+		return language
+	}
+
+	if language, ok := a.SupportedExtensions[extension]; ok {
+		if picked, ok := a.disambiguate(path, extension); ok {
+			return picked
+		}
+		return language
+	}
+
+	if filepath.Ext(path) == "" && len(a.Shebangs) > 0 {
+		return a.languageByShebang(path)
+	}
+
+	return ""
+}
+
+func (a *Analyzer) languageByFileName(name string) string {
+	if language, ok := a.FileNames[name]; ok {
+		return language
+	}
+
+	// Patterns are tried in order so that the result does not depend on the map order.
+	patterns := make([]string, 0, len(a.FileNames))
+	for pattern := range a.FileNames {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return a.FileNames[pattern]
+		}
+	}
+
+	return ""
+}
+
+func (a *Analyzer) languageByShebang(path string) string {
+	f, err := a.open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	line, _ := bufio.NewReaderSize(f, maxShebangLength).Peek(maxShebangLength)
+	if !strings.HasPrefix(string(line), "#!") {
+		return ""
+	}
+	if end := strings.IndexAny(string(line), "\r\n"); end >= 0 {
+		line = line[:end]
+	}
+
+	interpreter := shebangInterpreter(string(line[2:]))
+	if language, ok := a.Shebangs[interpreter]; ok {
+		return language
+	}
+
+	// python3.12 or ruby2.7 are declared as python or ruby
+	return a.Shebangs[strings.TrimRight(interpreter, "0123456789.")]
+}
+
+// shebangInterpreter returns the name of the interpreter of a "#!" line,
+// looking through "/usr/bin/env [-S] name".
+func shebangInterpreter(shebang string) string {
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"io"
+	"path/filepath"
+	"regexp"
+)
+
+// maxSniffedBytes bounds how much of a file the heuristics read.
+const maxSniffedBytes = 64 * 1024
+
+// heuristic is a rule choosing the language of a file whose extension is
+// shared. It matches when the head of the file holds one of its markers, or
+// when a file with one of its neighbor extensions sits in the same
+// directory, or always when it has neither. An empty language means that
+// the file belongs to none of the supported languages and is skipped.
+type heuristic struct {
+	language  string
+	markers   *regexp.Regexp
+	neighbors []string
+}
+
+// heuristics lists, by extension, the rules tried in order. A rule is only
+// tried when its language claims the extension.
+var heuristics = map[string][]heuristic{
+	".h": {
+		{language: "Objective-C", markers: regexp.MustCompile(`(?m)^\s*(#import\b|@(interface|protocol|end)\b)`)},
+		{language: "C++ Header", markers: regexp.MustCompile(`(?m)^\s*(class\s+\w+\s*[:{]|template\s*<|namespace\s+\w*\s*\{|(public|private|protected):|#include\s*<(iostream|string|vector|memory|map)>)`)},
+		{language: "Objective-C", neighbors: []string{".m", ".mm"}},
+		{language: "C++ Header", neighbors: []string{".cpp", ".cc", ".cxx", ".hpp"}},
+		{language: "C Header"},
+	},
+	".cls": {
+		{language: "VB6", markers: regexp.MustCompile(`(?m)^\s*(VERSION\s+1\.0\s+CLASS|Attribute\s+VB_)`)},
+		{language: "", markers: regexp.MustCompile(`(?m)^\s*\\(NeedsTeXFormat|ProvidesClass|LoadClass|DeclareOption)\b`)},
+		{language: "Apex"},
+	},
+	".m": {
+		{language: "Objective-C", markers: regexp.MustCompile(`(?m)^\s*(#import\b|#include\b|@(interface|implementation|protocol|end|property)\b)`)},
+		{language: "", markers: regexp.MustCompile(`(?m)^\s*(function\b|classdef\b|%)`)},
+		{language: "Objective-C"},
+	},
+	".pl": {
+		{language: "Perl", markers: regexp.MustCompile(`(?m)^\s*(use\s+(strict|warnings)\b|my\s+[$@%]|sub\s+\w+|package\s+[\w:]+;)`)},
+		{language: "", markers: regexp.MustCompile(`(?m)^\s*:-|^\s*\w+(\(.*\))?\s*:-`)},
+		{language: "Perl"},
+	},
+	".inc": {
+		{language: "PHP", markers: regexp.MustCompile(`<\?(php|=)`)},
+		{language: ""},
+	},
+}
+
+// disambiguate returns the language picked by the heuristics of the
+// extension among the candidates claiming it, and false when the extension
+// has no heuristics.
+func (a *Analyzer) disambiguate(path, extension string) (string, bool) {
+	rules, ok := heuristics[extension]
+	if !ok {
+		return "", false
+	}
+
+	candidates := map[string]bool{}
+	for _, language := range a.ExtensionLanguages[extension] {
+		candidates[language] = true
+	}
+	if len(candidates) == 0 {
+		candidates[a.SupportedExtensions[extension]] = true
+	}
+
+	var head []byte
+	for _, rule := range rules {
+		if rule.language != "" && !candidates[rule.language] {
+			continue
+		}
+
+		switch {
+		case rule.markers != nil:
+			if head == nil {
+				head = a.readHead(path)
+			}
+			if rule.markers.Match(head) {
+				return rule.language, true
+			}
+		case len(rule.neighbors) > 0:
+			if a.hasNeighbor(filepath.Dir(path), rule.neighbors) {
+				return rule.language, true
+			}
+		default:
+			return rule.language, true
+		}
+	}
+
+	return a.SupportedExtensions[extension], true
+}
+
+func (a *Analyzer) readHead(path string) []byte {
+	f, err := a.open(path)
+	if err != nil {
+		return []byte{}
+	}
+	defer f.Close()
+
+	head, _ := io.ReadAll(io.LimitReader(f, maxSniffedBytes))
+	return head
+}
+
+// hasNeighbor tells whether dir holds a file with one of the extensions,
+// listing each directory once per walk.
+func (a *Analyzer) hasNeighbor(dir string, extensions []string) bool {
+	found, ok := a.dirExtensions[dir]
+	if !ok {
+		found = map[string]bool{}
+		entries, _ := a.readDir(dir)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				found[filepath.Ext(entry.Name())] = true
+			}
+		}
+		a.dirExtensions[dir] = found
+	}
+
+	for _, extension := range extensions {
+		if found[extension] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import "bytes"
+
+// Text encodings told by DetectEncoding.
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF8BOM = "UTF-8 BOM"
+	EncodingUTF16LE = "UTF-16LE"
+	EncodingUTF16BE = "UTF-16BE"
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// DetectEncoding tells the encoding of a text file from its head: its byte
+// order mark or, without one, the NUL bytes of the ASCII characters of
+// UTF-16. Any other file is read as UTF-8.
+func DetectEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		return EncodingUTF8BOM
+	case bytes.HasPrefix(head, utf16LEBOM):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(head, utf16BEBOM):
+		return EncodingUTF16BE
+	}
+
+	pairs := len(head) / 2
+	if pairs < 2 {
+		return EncodingUTF8
+	}
+	evenNULs, oddNULs := 0, 0
+	for i := 0; i+1 < len(head); i += 2 {
+		if head[i] == 0 {
+			evenNULs++
+		}
+		if head[i+1] == 0 {
+			oddNULs++
+		}
+	}
+	// Most characters of source code are ASCII, whose high byte is NUL,
+	// while no character of UTF-16 has both bytes NUL.
+	switch {
+	case evenNULs == 0 && oddNULs*4 >= pairs*3:
+		return EncodingUTF16LE
+	case oddNULs == 0 && evenNULs*4 >= pairs*3:
+		return EncodingUTF16BE
+	}
+
+	return EncodingUTF8
+}
+
+// isUTF16 tells whether the encoding is one of UTF-16, whose NUL bytes are
+// not binary.
+func isUTF16(encoding string) bool {
+	return encoding == EncodingUTF16LE || encoding == EncodingUTF16BE
+}
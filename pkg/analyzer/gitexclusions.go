@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// Reasons recorded when a file is excluded by the repository's own git settings.
+const (
+	ExcludedByGitignore     = "gitignore"
+	ExcludedAsGenerated     = "linguist-generated"
+	ExcludedAsVendored      = "linguist-vendored"
+	ExcludedAsDocumentation = "linguist-documentation"
+)
+
+var linguistAttributes = []string{ExcludedAsGenerated, ExcludedAsVendored, ExcludedAsDocumentation}
+
+// gitExclusions matches the files a repository marks as ignored in its
+// .gitignore files, or as generated, vendored or documentation in its
+// .gitattributes files, at every directory level.
+type gitExclusions struct {
+	ignore     gitignore.Matcher
+	attributes []gitattributes.MatchAttribute
+}
+
+func loadGitExclusions(fs billy.Filesystem) (*gitExclusions, error) {
+	ignorePatterns, err := gitignore.ReadPatterns(fs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := gitattributes.ReadPatterns(fs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitExclusions{
+		ignore:     gitignore.NewMatcher(ignorePatterns),
+		attributes: attributes,
+	}, nil
+}
+
+// reason returns why the file at rel, relative to the repository root, is
+// excluded, or an empty string when it is not.
+func (g *gitExclusions) reason(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	if g.ignore.Match(parts, false) {
+		return ExcludedByGitignore
+	}
+
+	// Later .gitattributes lines and deeper files take precedence, like in git.
+	set := map[string]bool{}
+	for _, rule := range g.attributes {
+		if rule.Pattern == nil || !rule.Pattern.Match(parts) {
+			continue
+		}
+		for _, attribute := range rule.Attributes {
+			set[attribute.Name()] = attribute.IsSet() || (attribute.IsValueSet() && attribute.Value() == "true")
+		}
+	}
+
+	for _, name := range linguistAttributes {
+		if set[name] {
+			return name
+		}
+	}
+
+	return ""
+}
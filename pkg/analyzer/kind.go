@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of code a file holds, following SonarQube's split of main and test sources.
+const (
+	KindMain = "main"
+	KindTest = "test"
+)
+
+// kindOf returns KindTest when the file at rel, relative to the repository
+// root, matches one of the test patterns, and KindMain otherwise.
+func kindOf(rel string, patterns []string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, pattern := range patterns {
+		if matchesTestPattern(parts, pattern) {
+			return KindTest
+		}
+	}
+
+	return KindMain
+}
+
+// matchesTestPattern matches a pattern ending with "/" against the
+// directories of the path, at any depth, and any other pattern against
+// the file name.
+func matchesTestPattern(parts []string, pattern string) bool {
+	if !strings.HasSuffix(pattern, "/") {
+		ok, _ := filepath.Match(pattern, parts[len(parts)-1])
+		return ok
+	}
+
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	dirs := parts[:len(parts)-1]
+	for start := 0; start+len(segments) <= len(dirs); start++ {
+		if matchSegments(dirs[start:start+len(segments)], segments) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSegments(dirs, segments []string) bool {
+	for i, segment := range segments {
+		if ok, _ := filepath.Match(segment, dirs[i]); !ok {
+			return false
+		}
+	}
+
+	return true
+}
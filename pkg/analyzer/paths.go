@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// pathPattern is an include or exclude path pattern, relative to the
+// repository root. It supports "**" for any number of directories, and a
+// leading "!" negates it.
+type pathPattern struct {
+	glob    string
+	negated bool
+}
+
+// pathRules decide which files the include and exclude path patterns keep.
+// A pattern matches a file or any of its directories, so "vendor" excludes
+// everything under vendor/. In each list the last matching pattern wins, as
+// in .gitignore, so "!vendor/internal" keeps a directory of an excluded one.
+type pathRules struct {
+	include []pathPattern
+	exclude []pathPattern
+	// includes is set when at least one include pattern is not negated.
+	includes bool
+	// negatedExclude is set when an exclude pattern may keep a file of an
+	// excluded directory, which must then be walked.
+	negatedExclude bool
+}
+
+func newPathRules(include, exclude []string) (*pathRules, error) {
+	rules := &pathRules{}
+
+	var err error
+	if rules.include, err = parsePathPatterns(include); err != nil {
+		return nil, err
+	}
+	if rules.exclude, err = parsePathPatterns(exclude); err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range rules.include {
+		rules.includes = rules.includes || !pattern.negated
+	}
+	for _, pattern := range rules.exclude {
+		rules.negatedExclude = rules.negatedExclude || pattern.negated
+	}
+
+	return rules, nil
+}
+
+func parsePathPatterns(patterns []string) ([]pathPattern, error) {
+	var parsed []pathPattern
+	for _, pattern := range patterns {
+		p := pathPattern{glob: strings.TrimSpace(pattern)}
+		if strings.HasPrefix(p.glob, "!") {
+			p.negated = true
+			p.glob = strings.TrimSpace(p.glob[1:])
+		}
+		p.glob = strings.Trim(strings.TrimPrefix(filepath.ToSlash(p.glob), "./"), "/")
+		if p.glob == "" {
+			continue
+		}
+		if !doublestar.ValidatePattern(p.glob) {
+			return nil, fmt.Errorf("invalid path pattern %q", pattern)
+		}
+		parsed = append(parsed, p)
+	}
+
+	return parsed, nil
+}
+
+// keeps tells whether the file at rel, relative to the repository root,
+// is analyzed.
+func (r *pathRules) keeps(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if r.includes && !lastMatch(r.include, rel) {
+		return false
+	}
+
+	return !lastMatch(r.exclude, rel)
+}
+
+// skipsDir tells whether no file under the directory at rel can be kept.
+func (r *pathRules) skipsDir(rel string) bool {
+	return !r.negatedExclude && lastMatch(r.exclude, filepath.ToSlash(rel))
+}
+
+// lastMatch tells whether the last pattern matching rel is not negated.
+func lastMatch(patterns []pathPattern, rel string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if pattern.matches(rel) {
+			matched = !pattern.negated
+		}
+	}
+
+	return matched
+}
+
+// matches tells whether the pattern matches rel or one of its directories.
+func (p pathPattern) matches(rel string) bool {
+	for ; rel != "." && rel != "/" && rel != ""; rel = path.Dir(rel) {
+		if ok, _ := doublestar.Match(p.glob, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// Reasons recorded when a file with a supported extension is not counted.
+const (
+	SkippedTooLarge = "too-large"
+	SkippedBinary   = "binary"
+)
+
+// binarySniffLength is how much of the head of a file is searched for a
+// NUL byte, as git does to tell binary files.
+const binarySniffLength = 8000
+
+// SkippedFile is a file left out of the count, and the reason why.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// skipReason returns why the file must not be counted, or an empty string.
+func (a *Analyzer) skipReason(path string, info fs.FileInfo) string {
+	if a.MaxFileSize > 0 && info.Size() > a.MaxFileSize {
+		return SkippedTooLarge
+	}
+	if a.isBinary(path) {
+		return SkippedBinary
+	}
+
+	return ""
+}
+
+// isBinary tells whether the head of the file holds a NUL byte, unless it
+// is UTF-16 text.
+func (a *Analyzer) isBinary(path string) bool {
+	f, err := a.open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head, _ := io.ReadAll(io.LimitReader(f, binarySniffLength))
+	if isUTF16(DetectEncoding(head)) {
+		return false
+	}
+
+	return bytes.IndexByte(head, 0) >= 0
+}
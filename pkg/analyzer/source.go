@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+)
+
+// gitSettingFiles are the files of the repository read for GitExclusions.
+var gitSettingFiles = map[string]bool{".gitignore": true, ".gitattributes": true}
+
+// Open opens the file, from the file source it was found in, or else from
+// the OS file system.
+func (f FileMetadata) Open() (fs.File, error) {
+	if f.source != nil {
+		return f.source.Open(f.name)
+	}
+
+	return os.Open(f.FilePath)
+}
+
+// walk walks the files of the analyzer, from FS when set. The paths given to
+// fn are then those of the files under the analyzer path, as if they were
+// checked out there.
+func (a *Analyzer) walk(fn filepath.WalkFunc) error {
+	if a.FS == nil {
+		return filepath.Walk(a.path, fn)
+	}
+
+	return fs.WalkDir(a.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		path := filepath.Join(a.path, filepath.FromSlash(name))
+		if err != nil {
+			return fn(path, nil, err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fn(path, nil, err)
+		}
+
+		return fn(path, info, nil)
+	})
+}
+
+// open opens the file at path, from FS when set.
+func (a *Analyzer) open(path string) (fs.File, error) {
+	if a.FS == nil {
+		return os.Open(path)
+	}
+
+	return a.FS.Open(a.sourceName(path))
+}
+
+// readDir lists the directory at path, from FS when set.
+func (a *Analyzer) readDir(path string) ([]fs.DirEntry, error) {
+	if a.FS == nil {
+		return os.ReadDir(path)
+	}
+
+	return fs.ReadDir(a.FS, a.sourceName(path))
+}
+
+// sourceName returns the name in FS of the file at path.
+func (a *Analyzer) sourceName(path string) string {
+	rel, err := filepath.Rel(a.path, path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// metadata returns the metadata of the file at path, which reads it from FS
+// when set.
+func (a *Analyzer) metadata(path string) FileMetadata {
+	file := FileMetadata{FilePath: path}
+	if a.FS != nil {
+		file.source = a.FS
+		file.name = a.sourceName(path)
+	}
+
+	return file
+}
+
+// gitSettings returns the file system the git exclusions are read from. The
+// .gitignore and .gitattributes files of FS are copied in memory, since the
+// go-git readers need a billy file system.
+func (a *Analyzer) gitSettings() (billy.Filesystem, error) {
+	if a.FS == nil {
+		return osfs.New(a.path), nil
+	}
+
+	settings := memfs.New()
+	err := fs.WalkDir(a.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !gitSettingFiles[path.Base(name)] || strings.HasPrefix(name, ".git/") {
+			return err
+		}
+		data, err := fs.ReadFile(a.FS, name)
+		if err != nil {
+			return err
+		}
+		file, err := settings.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return err
+		}
+
+		return file.Close()
+	})
+
+	return settings, err
+}
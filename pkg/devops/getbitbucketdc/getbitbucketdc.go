@@ -20,6 +20,9 @@ type ProjectBranch struct {
 	RepoSlug    string
 	MainBranch  string
 	LargestSize int
+	// SSHURL is the ssh:// clone link of the repository, empty when SSH is
+	// disabled on the server.
+	SSHURL string
 }
 
 type RepositoryData struct {
@@ -84,9 +87,24 @@ type Repo struct {
 		Self []struct {
 			Href string `json:"href"`
 		} `json:"self"`
+		Clone []struct {
+			Href string `json:"href"`
+			Name string `json:"name"`
+		} `json:"clone"`
 	} `json:"links"`
 }
 
+// sshCloneURL returns the ssh:// clone link of the repository, if any.
+func (r Repo) sshCloneURL() string {
+	for _, link := range r.Links.Clone {
+		if link.Name == "ssh" {
+			return link.Href
+		}
+	}
+
+	return ""
+}
+
 type ProjectRepo struct {
 	Type string `json:"type"`
 	Key  string `json:"key"`
@@ -282,6 +300,7 @@ func processRepo(projectKey string, repo Repo, parms ParamsReposProjectDC, bitbu
 		RepoSlug:    repo.Slug,
 		MainBranch:  largestRepoBranch,
 		LargestSize: largestRepoSize,
+		SSHURL:      repo.sshCloneURL(),
 	})
 
 	return nil
@@ -384,6 +403,7 @@ func GetRepos(project string, repos []Repo, parms ParamsReposDC, bitbucketURLBas
 			RepoSlug:    repo.Slug,
 			MainBranch:  largestRepoBranch,
 			LargestSize: largestRepoSize,
+			SSHURL:      repo.sshCloneURL(),
 		})
 	}
 
@@ -19,6 +19,8 @@ type ProjectBranch struct {
 	RepoSlug    string
 	MainBranch  string
 	LargestSize int
+	// SSHURL is the scp-style SSH clone link of the project.
+	SSHURL string
 }
 
 type ExclusionList struct {
@@ -379,6 +381,7 @@ func analyzeProj(analyzeProject AnalyzeProject) (ProjectBranch, int, int, int) {
 		RepoSlug:    analyzeProject.Project.Name,
 		MainBranch:  largestBranch,
 		LargestSize: largestSize,
+		SSHURL:      analyzeProject.Project.SSHURLToRepo,
 	}
 
 	return projectBranches, 0, 0, 0
@@ -527,6 +530,7 @@ func analyzeMainBranchForProjects(client *gitlab.Client, projects []*gitlab.Proj
 					RepoSlug:    project.Name,
 					MainBranch:  mainBranch,
 					LargestSize: largestSize,
+					SSHURL:      project.SSHURLToRepo,
 				})
 		totalBranches += nbrsize
 				spin1.Stop()
@@ -558,6 +562,7 @@ func analyzeSpecificBranchForProjects(client *gitlab.Client, projects []*gitlab.
 			RepoSlug:    project.Name,
 			MainBranch:  branch,
 			LargestSize: largestSize,
+			SSHURL:      project.SSHURLToRepo,
 		})
 		spin1.Stop()
 		loggers.Infof(Message3, cpt, project.Name, 1, branch)
@@ -656,6 +661,7 @@ func nonDefaultSpecificProjectSpecificBranch(ctx nonDefaultCtx) ([]ProjectBranch
 			RepoSlug:    ctx.config["Project"].(string),
 			MainBranch:  branch,
 			LargestSize: 1,
+			SSHURL:      project.SSHURLToRepo,
 		})
 		totalBranches = 1
 	} else {
@@ -0,0 +1,315 @@
+package gogit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// unsafeNameChars are the characters of a key replaced in the name of its
+// cache directory.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CacheKey identifies the clone of a branch of a repository in a Cache.
+type CacheKey struct {
+	Platform   string
+	Repository string
+	Branch     string
+}
+
+// dir returns the directory of the key under the cache directory. The hash
+// of the key tells apart the keys whose names are the same once cleaned.
+func (k CacheKey) dir() string {
+	sum := sha256.Sum256([]byte(k.Platform + "\x00" + k.Repository + "\x00" + k.Branch))
+	name := fmt.Sprintf("%s_%s_%s", safeName(k.Repository), safeName(k.Branch), hex.EncodeToString(sum[:4]))
+
+	return filepath.Join(safeName(k.Platform), name)
+}
+
+func safeName(name string) string {
+	if name = unsafeNameChars.ReplaceAllString(name, "_"); name == "" {
+		return "_"
+	}
+
+	return name
+}
+
+// Cache keeps the shallow clones of the repositories analyzed in a directory
+// across runs. A clone found in the cache is updated with a shallow fetch of
+// its branch instead of being cloned again. Once the cache grows over
+// MaxSize, the least recently used clones not in use are evicted.
+type Cache struct {
+	Dir string
+	// MaxSize is the size of the cache in bytes, not limited when zero.
+	MaxSize int64
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a clone of the cache. Its lock is held from Checkout until
+// Release, so that a clone is updated and analyzed by one run at a time.
+type cacheEntry struct {
+	path  string
+	size  int64
+	used  time.Time
+	users int
+	lock  sync.Mutex
+}
+
+// NewCache opens the cache in dir, creating it when missing, and lists the
+// clones it already holds.
+func NewCache(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("❌ failed to create cache directory %s: %v", dir, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{Dir: dir, MaxSize: maxSize, entries: map[string]*cacheEntry{}}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		size, err := dirSize(path)
+		if err != nil {
+			return nil, err
+		}
+		c.entries[path] = &cacheEntry{path: path, size: size, used: info.ModTime()}
+	}
+
+	return c, nil
+}
+
+// Checkout returns the path of the clone of the branch of the repository at
+// src, authenticated by credential. The clone of the cache is updated to the
+// last commit of the branch, or cloned when missing or unusable. The clone
+// is kept from eviction until it is given back to Release.
+func (c *Cache) Checkout(key CacheKey, src string, credential Credential) (string, error) {
+	path := filepath.Join(c.Dir, key.dir())
+
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	if !ok {
+		entry = &cacheEntry{path: path}
+		c.entries[path] = entry
+	}
+	entry.users++
+	c.mu.Unlock()
+	entry.lock.Lock()
+
+	err := update(path, src, key.Branch, credential)
+	if err != nil {
+		c.Release(path)
+		return "", err
+	}
+
+	size, err := dirSize(path)
+	if err != nil {
+		c.Release(path)
+		return "", err
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	c.mu.Lock()
+	entry.size, entry.used = size, now
+	if c.MaxSize > 0 {
+		_, err = c.evict(c.MaxSize)
+	}
+	c.mu.Unlock()
+	if err != nil {
+		c.Release(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Release gives back the clone at path returned by Checkout.
+func (c *Cache) Release(path string) {
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	if ok {
+		entry.users--
+	}
+	c.mu.Unlock()
+
+	if ok {
+		entry.lock.Unlock()
+	}
+}
+
+// Prune evicts the least recently used clones not in use until the cache is
+// no larger than maxSize, and returns the paths of the evicted clones. A
+// maxSize of zero empties the cache.
+func (c *Cache) Prune(maxSize int64) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted, err := c.evict(maxSize)
+	if err != nil {
+		return evicted, err
+	}
+	// Remove the platform directories left empty.
+	dirs, _ := filepath.Glob(filepath.Join(c.Dir, "*"))
+	for _, dir := range dirs {
+		_ = os.Remove(dir)
+	}
+
+	return evicted, nil
+}
+
+// Size returns the size of the clones of the cache in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size()
+}
+
+func (c *Cache) size() int64 {
+	var size int64
+	for _, entry := range c.entries {
+		size += entry.size
+	}
+
+	return size
+}
+
+// evict removes the least recently used clones not in use while the cache
+// is larger than maxSize. The lock of the cache must be held.
+func (c *Cache) evict(maxSize int64) ([]string, error) {
+	var idle []*cacheEntry
+	for _, entry := range c.entries {
+		if entry.users == 0 {
+			idle = append(idle, entry)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].used.Before(idle[j].used) })
+
+	var evicted []string
+	size := c.size()
+	for _, entry := range idle {
+		if size <= maxSize {
+			break
+		}
+		if err := os.RemoveAll(entry.path); err != nil {
+			return evicted, fmt.Errorf("❌ failed to evict %s from the cache: %v", entry.path, err)
+		}
+		delete(c.entries, entry.path)
+		size -= entry.size
+		evicted = append(evicted, entry.path)
+	}
+
+	return evicted, nil
+}
+
+// update brings the clone at path to the last commit of the branch of src
+// with a shallow fetch, or clones it again when that fails.
+func update(path, src, branch string, credential Credential) error {
+	auth, err := credential.AuthMethod()
+	if err != nil {
+		return err
+	}
+
+	if err := fetch(path, src, branch, auth); err == nil {
+		return nil
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	_, err = git.PlainClone(path, false, &git.CloneOptions{
+		URL:           src,
+		Auth:          auth,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         1,
+	})
+	if err != nil {
+		os.RemoveAll(path)
+		return fmt.Errorf("❌ failed to clone branch %s of %s: %v", branch, src, err)
+	}
+
+	return nil
+}
+
+// fetch fetches the last commit of the branch into the clone at path, and
+// resets its worktree to it. The clone must come from src.
+func fetch(path, src, branch string, auth transport.AuthMethod) error {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	if urls := remote.Config().URLs; len(urls) == 0 || urls[0] != src {
+		return fmt.Errorf("clone of %v, not of %s", urls, src)
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch)
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef))},
+		Auth:       auth,
+		Depth:      1,
+		Force:      true,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset}); err != nil {
+		return err
+	}
+
+	return worktree.Clean(&git.CleanOptions{Dir: true})
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,136 @@
+package gogit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCacheCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+	src := commitRepo(t, map[string]string{"main.go": "package main\n"})
+	cache, err := NewCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := CacheKey{Platform: "gitlab", Repository: "group/app", Branch: "master"}
+
+	path, err := cache.Checkout(key, src, Credential{})
+	if err != nil {
+		t.Fatalf("Checkout error: %v", err)
+	}
+	cache.Release(path)
+	if filepath.Dir(path) != filepath.Join(cache.Dir, "gitlab") {
+		t.Errorf("unexpected path %s", path)
+	}
+	marker := filepath.Join(path, ".git", "golc-marker")
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "stale.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new commit is fetched into the same clone.
+	repo, err := git.PlainOpen(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "golc", Email: "golc@example.com", When: time.Now()}
+	if _, err := worktree.Commit("update", &git.CommitOptions{All: true, Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := cache.Checkout(key, src, Credential{})
+	if err != nil {
+		t.Fatalf("Checkout error: %v", err)
+	}
+	cache.Release(again)
+	if again != path {
+		t.Errorf("Checkout = %s, want %s", again, path)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("the clone should be fetched, not cloned again")
+	}
+	if _, err := os.Stat(filepath.Join(path, "stale.go")); !os.IsNotExist(err) {
+		t.Error("the files not in the branch should be removed")
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "main.go")); err != nil || string(data) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("main.go = %q, %v", data, err)
+	}
+
+	if _, err := cache.Checkout(CacheKey{Platform: "gitlab", Repository: "group/app", Branch: "missing"}, src, Credential{}); err == nil {
+		t.Error("Checkout should fail on a missing branch")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+	src := commitRepo(t, map[string]string{"main.go": "package main\n"})
+	dir := t.TempDir()
+	cache, err := NewCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := cache.Checkout(CacheKey{Platform: "github", Repository: "org/first", Branch: "master"}, src, Credential{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Release(first)
+	// Only one clone fits in the cache from now on.
+	cache.MaxSize = cache.Size()
+	second, err := cache.Checkout(CacheKey{Platform: "github", Repository: "org/second", Branch: "master"}, src, Credential{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("the least recently used clone should be evicted")
+	}
+	if evicted, err := cache.Prune(0); err != nil || len(evicted) != 0 {
+		t.Errorf("Prune = %v, %v: a clone in use should be kept", evicted, err)
+	}
+	cache.Release(second)
+
+	reopened, err := NewCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Size() != cache.Size() {
+		t.Errorf("Size = %d, want %d", reopened.Size(), cache.Size())
+	}
+	evicted, err := reopened.Prune(0)
+	if err != nil || len(evicted) != 1 || evicted[0] != second {
+		t.Errorf("Prune = %v, %v", evicted, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Prune should empty the cache, found %v", entries)
+	}
+}
+
+func TestCacheKeyDir(t *testing.T) {
+	a := CacheKey{Platform: "Bitbucket DC", Repository: "proj/repo", Branch: "feature/x"}.dir()
+	b := CacheKey{Platform: "Bitbucket DC", Repository: "proj_repo", Branch: "feature/x"}.dir()
+	if a == b {
+		t.Errorf("keys should not share %s", a)
+	}
+	if filepath.Dir(a) != "Bitbucket_DC" || filepath.Base(filepath.Dir(a)) != filepath.Dir(a) {
+		t.Errorf("unexpected dir %s", a)
+	}
+}
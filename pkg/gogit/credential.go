@@ -0,0 +1,172 @@
+package gogit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/skeema/knownhosts"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// CredentialKind tells how a Credential authenticates.
+type CredentialKind int
+
+const (
+	// NoCredential clones anonymously.
+	NoCredential CredentialKind = iota
+	// TokenCredential sends an access token as the password of HTTP basic auth.
+	TokenCredential
+	// BasicCredential sends a username and a password with HTTP basic auth.
+	BasicCredential
+	// SSHKeyCredential signs in with a private SSH key.
+	SSHKeyCredential
+	// SSHAgentCredential signs in with the keys of the running ssh-agent.
+	SSHAgentCredential
+)
+
+// defaultUsername is the username sent with a token or an SSH key when none
+// is given.
+const defaultUsername = "git"
+
+// Credential authenticates the clone of a repository, so that no secret is
+// ever put in its URL. Its String method hides the secret from the logs.
+type Credential struct {
+	Kind     CredentialKind
+	Username string
+	// Secret is the token, the password or the passphrase of the SSH key.
+	Secret string
+	// KeyPath is the path of the private SSH key.
+	KeyPath string
+	// KnownHosts is the known_hosts file the SSH host keys are verified
+	// with. When empty, the files of SSH_KNOWN_HOSTS are used, or else
+	// ~/.ssh/known_hosts and /etc/ssh/ssh_known_hosts.
+	KnownHosts string
+}
+
+// Token returns the credential of an access token. The platforms expect a
+// fixed username with some tokens, such as "gitlab-ci-token".
+func Token(username, token string) Credential {
+	return Credential{Kind: TokenCredential, Username: username, Secret: token}
+}
+
+// Basic returns the credential of a username and a password.
+func Basic(username, password string) Credential {
+	return Credential{Kind: BasicCredential, Username: username, Secret: password}
+}
+
+// SSHKey returns the credential of the private SSH key at keyPath, and of
+// its passphrase if it is encrypted.
+func SSHKey(keyPath, passphrase string) Credential {
+	return Credential{Kind: SSHKeyCredential, KeyPath: keyPath, Secret: passphrase}
+}
+
+// SSHAgent returns the credential of the keys of the ssh-agent listening on
+// SSH_AUTH_SOCK.
+func SSHAgent() Credential {
+	return Credential{Kind: SSHAgentCredential}
+}
+
+// IsSSH tells whether the credential needs an SSH clone URL.
+func (c Credential) IsSSH() bool {
+	return c.Kind == SSHKeyCredential || c.Kind == SSHAgentCredential
+}
+
+// AuthMethod returns the go-git authentication of the credential, nil
+// without one.
+func (c Credential) AuthMethod() (transport.AuthMethod, error) {
+	switch c.Kind {
+	case NoCredential:
+		return nil, nil
+	case TokenCredential, BasicCredential:
+		return &http.BasicAuth{Username: c.username(), Password: c.Secret}, nil
+	case SSHKeyCredential:
+		auth, err := ssh.NewPublicKeysFromFile(c.username(), c.KeyPath, c.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("❌ failed to load SSH key %s: %v", c.KeyPath, err)
+		}
+		auth.HostKeyCallback, err = c.hostKeyCallback()
+		return auth, err
+	case SSHAgentCredential:
+		auth, err := ssh.NewSSHAgentAuth(c.username())
+		if err != nil {
+			return nil, fmt.Errorf("❌ failed to reach ssh-agent: %v", err)
+		}
+		auth.HostKeyCallback, err = c.hostKeyCallback()
+		return auth, err
+	default:
+		return nil, fmt.Errorf("❌ unknown credential kind %d", c.Kind)
+	}
+}
+
+// hostKeyCallback verifies the SSH host keys with the known_hosts files, so
+// that a clone fails on an unknown or changed host key.
+func (c Credential) hostKeyCallback() (gossh.HostKeyCallback, error) {
+	files, err := c.knownHostsFiles()
+	if err != nil {
+		return nil, err
+	}
+	db, err := knownhosts.NewDB(files...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ failed to read known_hosts: %v", err)
+	}
+
+	return db.HostKeyCallback(), nil
+}
+
+// knownHostsFiles returns KnownHosts, or else the existing files of
+// SSH_KNOWN_HOSTS or the default ones, as ssh does.
+func (c Credential) knownHostsFiles() ([]string, error) {
+	if c.KnownHosts != "" {
+		return []string{c.KnownHosts}, nil
+	}
+
+	candidates := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS"))
+	if len(candidates) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		candidates = []string{filepath.Join(home, ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"}
+	}
+
+	var files []string
+	for _, file := range candidates {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("❌ no known_hosts file found in %v", candidates)
+	}
+
+	return files, nil
+}
+
+func (c Credential) username() string {
+	if c.Username == "" {
+		return defaultUsername
+	}
+
+	return c.Username
+}
+
+func (c Credential) String() string {
+	switch c.Kind {
+	case NoCredential:
+		return "none"
+	case TokenCredential:
+		return fmt.Sprintf("token of %s", c.username())
+	case BasicCredential:
+		return fmt.Sprintf("password of %s", c.username())
+	case SSHKeyCredential:
+		return fmt.Sprintf("SSH key %s", c.KeyPath)
+	case SSHAgentCredential:
+		return "ssh-agent"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,102 @@
+package gogit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestCredentialAuthMethod(t *testing.T) {
+	if auth, err := (Credential{}).AuthMethod(); auth != nil || err != nil {
+		t.Errorf("no credential = %v, %v, want nil", auth, err)
+	}
+
+	tests := []struct {
+		credential Credential
+		want       http.BasicAuth
+	}{
+		{Token("gitlab-ci-token", "secret"), http.BasicAuth{Username: "gitlab-ci-token", Password: "secret"}},
+		{Token("", "secret"), http.BasicAuth{Username: "git", Password: "secret"}},
+		{Basic("john", "secret"), http.BasicAuth{Username: "john", Password: "secret"}},
+	}
+	for _, tt := range tests {
+		auth, err := tt.credential.AuthMethod()
+		if err != nil {
+			t.Fatalf("AuthMethod(%v) error: %v", tt.credential, err)
+		}
+		if basic, ok := auth.(*http.BasicAuth); !ok || *basic != tt.want {
+			t.Errorf("AuthMethod(%v) = %#v, want %#v", tt.credential, auth, tt.want)
+		}
+	}
+
+	if _, err := SSHKey("/nonexistent/id_ed25519", "").AuthMethod(); err == nil {
+		t.Error("AuthMethod should fail on a missing SSH key")
+	}
+}
+
+func TestCredentialHidesSecret(t *testing.T) {
+	for _, credential := range []Credential{Token("x-token-auth", "secret"), Basic("john", "secret"), SSHKey("id_rsa", "secret")} {
+		if got := fmt.Sprintf("%v %+v", credential, struct{ Auth Credential }{credential}); strings.Contains(got, "secret") {
+			t.Errorf("the secret is printed: %s", got)
+		}
+	}
+}
+
+func TestCredentialSSHKey(t *testing.T) {
+	dir := t.TempDir()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := gossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	knownHosts := filepath.Join(dir, "known_hosts")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(knownHosts, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	credential := SSHKey(keyPath, "")
+	credential.KnownHosts = knownHosts
+	auth, err := credential.AuthMethod()
+	if err != nil {
+		t.Fatalf("AuthMethod error: %v", err)
+	}
+	if keys, ok := auth.(*ssh.PublicKeys); !ok || keys.User != "git" || keys.HostKeyCallback == nil {
+		t.Errorf("AuthMethod = %#v, want the public keys of git verifying host keys", auth)
+	}
+
+	credential.KnownHosts = filepath.Join(dir, "missing")
+	if _, err := credential.AuthMethod(); err == nil {
+		t.Error("AuthMethod should fail without a known_hosts file")
+	}
+}
+
+func TestIsSSHURL(t *testing.T) {
+	tests := map[string]bool{
+		"ssh://git@bitbucket.example.com:7999/proj/repo.git": true,
+		"git@gitlab.example.com:group/project.git":           true,
+		"https://gitlab.example.com/group/project.git":       false,
+		"/tmp/repo": false,
+		"":          false,
+	}
+	for url, want := range tests {
+		if got := IsSSHURL(url); got != want {
+			t.Errorf("IsSSHURL(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -4,21 +4,33 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 
 	"github.com/SonarSource-Demos/sonar-golc/pkg/utils"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 	//"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-func Getrepos(src, branch, token string) (string, error) {
+// The clones of Getrepos, GetTree and Cache do not support thin packs. The
+// capabilities are set once, as clones run concurrently.
+func init() {
+	transport.UnsupportedCapabilities = []capability.Capability{
+		capability.ThinPack,
+	}
+}
+
+// Getrepos clones the branch of the repository at src, authenticated by
+// credential, in a temporary directory and returns its path.
+func Getrepos(src, branch string, credential Credential) (string, error) {
 
 	loggers := utils.NewLogger()
 	suffix, err := randomSuffix()
@@ -33,12 +45,14 @@ func Getrepos(src, branch, token string) (string, error) {
 	}
 	log.SetOutput(os.Stderr)
 
-	transport.UnsupportedCapabilities = []capability.Capability{
-		capability.ThinPack,
+	auth, err := credential.AuthMethod()
+	if err != nil {
+		return "", err
 	}
 
 	_, err = git.PlainClone(dst, false, &git.CloneOptions{
-		URL: src,
+		URL:  src,
+		Auth: auth,
 
 		ReferenceName: plumbing.NewBranchReferenceName(branch),
 		//ReferenceName: plumbing.ReferenceName(branch),
@@ -48,10 +62,8 @@ func Getrepos(src, branch, token string) (string, error) {
 	})
 
 	if err != nil {
-		re := regexp.MustCompile(`(https?:\/\/)[^@]+(@)`)
-		maskedSrc := re.ReplaceAllString(src, "${1}*****${2}")
-		//fmt.Printf("\n--❌ Stack: gogit.Getrepos Git Branch %s - %s-- Source: %s -", plumbing.Main, err, maskedSrc)
-		loggers.Errorf("\r\t\t\t\t❌ Stack: gogit.Getrepos Git Branch %s - %s-- Source: %s -", plumbing.Main, err, maskedSrc)
+		//fmt.Printf("\n--❌ Stack: gogit.Getrepos Git Branch %s - %s-- Source: %s -", plumbing.Main, err, src)
+		loggers.Errorf("\r\t\t\t\t❌ Stack: gogit.Getrepos Git Branch %s - %s-- Source: %s -", plumbing.Main, err, src)
 
 	}
 
@@ -72,6 +84,100 @@ func Getrepos(src, branch, token string) (string, error) {
 	return dst, nil
 }
 
+// GetTree clones the branch of the repository at src into memory, without
+// checkout, and returns the files of its last commit, read straight from the
+// git objects, and the hash of that commit. The files are reported under the
+// returned path, which is not created on disk.
+func GetTree(src, branch string, credential Credential) (string, fs.FS, string, error) {
+	suffix, err := randomSuffix()
+	if err != nil {
+		return "", nil, "", err
+	}
+	dst := filepath.Join(os.TempDir(), fmt.Sprintf("gcloc-extract-%s", suffix))
+
+	auth, err := credential.AuthMethod()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           src,
+		Auth:          auth,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         1,
+	})
+	if err != nil {
+		return "", nil, "", fmt.Errorf("❌ failed to clone branch %s of %s: %v", branch, src, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", nil, "", err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", nil, "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", nil, "", err
+	}
+	files, err := newTreeFS(tree)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return dst, files, head.Hash().String(), nil
+}
+
+// HeadCommit returns the hash of the commit checked out in the repository at
+// path.
+func HeadCommit(path string) (string, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
+// RemoteCommit returns the hash of the last commit of the branch of the
+// repository at src, listed from its references as git ls-remote does,
+// without fetching anything.
+func RemoteCommit(src, branch string, credential Credential) (string, error) {
+	auth, err := credential.AuthMethod()
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{src}})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("❌ failed to list the references of %s: %v", src, err)
+	}
+	name := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("❌ branch %s not found in %s", branch, src)
+}
+
+// IsSSHURL tells whether url is an ssh:// URL or an scp-style one, such as
+// "git@gitlab.example.com:group/project.git".
+func IsSSHURL(url string) bool {
+	endpoint, err := transport.NewEndpoint(url)
+
+	return url != "" && err == nil && endpoint.Protocol == "ssh"
+}
+
 func randomSuffix() (string, error) {
 	randBytes := make([]byte, 16)
 	_, err := rand.Read(randBytes)
@@ -0,0 +1,156 @@
+package gogit
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// treeFS is the read-only file system of the files of a commit tree, whose
+// contents are read from the git objects of the repository, without any
+// checkout. Symbolic links and submodules are left out.
+type treeFS struct {
+	files map[string]*object.File
+	dirs  map[string][]fs.DirEntry
+}
+
+func newTreeFS(tree *object.Tree) (*treeFS, error) {
+	t := &treeFS{
+		files: map[string]*object.File{},
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+
+	err := tree.Files().ForEach(func(file *object.File) error {
+		if !file.Mode.IsFile() {
+			return nil
+		}
+		t.files[file.Name] = file
+		t.addEntry(file.Name, fs.FileInfoToDirEntry(fileInfo{name: path.Base(file.Name), size: file.Size}))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entries := range t.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+
+	return t, nil
+}
+
+// addEntry lists the entry at name in its directory, adding the missing
+// parent directories.
+func (t *treeFS) addEntry(name string, entry fs.DirEntry) {
+	dir := path.Dir(name)
+	if _, ok := t.dirs[dir]; !ok {
+		t.dirs[dir] = nil
+		t.addEntry(dir, fs.FileInfoToDirEntry(fileInfo{name: path.Base(dir), dir: true}))
+	}
+	t.dirs[dir] = append(t.dirs[dir], entry)
+}
+
+func (t *treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entries, ok := t.dirs[name]; ok {
+		return &treeDir{info: fileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+	}
+
+	file, ok := t.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &treeFile{info: fileInfo{name: path.Base(name), size: file.Size}, ReadCloser: reader}, nil
+}
+
+func (t *treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := t.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]fs.DirEntry{}, entries...), nil
+}
+
+func (t *treeFS) Stat(name string) (fs.FileInfo, error) {
+	if _, ok := t.dirs[name]; ok {
+		return fileInfo{name: path.Base(name), dir: true}, nil
+	}
+	if file, ok := t.files[name]; ok {
+		return fileInfo{name: path.Base(name), size: file.Size}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+type treeFile struct {
+	info fileInfo
+	io.ReadCloser
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+type treeDir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) Close() error {
+	return nil
+}
+
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	d.offset += len(rest)
+
+	return append([]fs.DirEntry{}, rest...), nil
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() interface{}   { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
@@ -0,0 +1,125 @@
+package gogit
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func writeRepoFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// commitRepo commits the files in a new repository and returns its path.
+func commitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeRepoFiles(t, root, files)
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range files {
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature := &object.Signature{Name: "golc", Email: "golc@example.com", When: time.Now()}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+var treeFiles = map[string]string{
+	"main.go":          "package main\n",
+	"src/app/util.go":  "package app\n",
+	"src/app/util.css": "a {}\n",
+	"docs/README.md":   "# docs\n",
+}
+
+func TestTreeFS(t *testing.T) {
+	repo, err := git.PlainOpen(commitRepo(t, treeFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := newTreeFS(tree)
+	if err != nil {
+		t.Fatalf("newTreeFS error: %v", err)
+	}
+	if err := fstest.TestFS(files, "main.go", "src/app/util.go", "src/app/util.css", "docs/README.md"); err != nil {
+		t.Error(err)
+	}
+	if data, err := fs.ReadFile(files, "src/app/util.go"); err != nil || string(data) != treeFiles["src/app/util.go"] {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
+
+func TestGetTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+	dir := commitRepo(t, treeFiles)
+
+	path, files, commit, err := GetTree(dir, "master", Credential{})
+	if err != nil {
+		t.Fatalf("GetTree error: %v", err)
+	}
+	if head, err := HeadCommit(dir); err != nil || commit != head {
+		t.Errorf("GetTree commit = %s, want %s (%v)", commit, head, err)
+	}
+	if remote, err := RemoteCommit(dir, "master", Credential{}); err != nil || remote != commit {
+		t.Errorf("RemoteCommit = %s, %v, want %s", remote, err, commit)
+	}
+	if !strings.HasPrefix(filepath.Base(path), "gcloc-extract-") {
+		t.Errorf("unexpected path %s", path)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("GetTree should not write to %s", path)
+	}
+	if data, err := fs.ReadFile(files, "main.go"); err != nil || string(data) != treeFiles["main.go"] {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+
+	if _, _, _, err := GetTree(dir, "missing", Credential{}); err == nil {
+		t.Error("GetTree should fail on a missing branch")
+	}
+	if _, err := RemoteCommit(dir, "missing", Credential{}); err == nil {
+		t.Error("RemoteCommit should fail on a missing branch")
+	}
+}
@@ -2,10 +2,12 @@ package goloc
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
-	"github.com/SonarSource-Demos/sonar-golc/pkg/filesystem"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/getter"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/gogit"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
@@ -24,6 +26,7 @@ type Params struct {
 	ByFile            bool
 	ByAll             bool
 	ExcludePaths      []string
+	IncludePaths      []string
 	ExcludeExtensions []string
 	IncludeExtensions []string
 	OrderByLang       bool
@@ -37,18 +40,50 @@ type Params struct {
 	OutputPath        string
 	ReportFormats     []string
 	Branch            string
-	Token             string
+	Credential        gogit.Credential
 	Cloned            bool
 	Repopath          string
+	ScanWorkers       int
+	GitExclusions     bool
+	GeneratedCode     string
+	TestPatterns      []string
+	// MaxFileSize, in bytes, and MaxLineLength skip the larger files and the
+	// files with longer lines, when positive.
+	MaxFileSize   int64
+	MaxLineLength int
+	// InMemory clones the branch into memory and analyzes the files of its
+	// last commit without checking them out on disk.
+	InMemory bool
+	// Cache keeps the clone of the branch across runs under CacheKey, when
+	// set and the branch is not analyzed InMemory.
+	Cache    *gogit.Cache
+	CacheKey gogit.CacheKey
 }
 
 type GCloc struct {
-	Params    Params
-	analyzer  *analyzer.Analyzer
-	scanner   *scanner.Scanner
+	Params   Params
+	analyzer *analyzer.Analyzer
+	scanner  *scanner.Scanner
+	reports  []reportSet
+	Repopath string
+	// Rules are the rules applied to the analysis, nil when there are none.
+	Rules *AppliedRules
+	// FS holds the files of a repository read into memory, nil when they
+	// are on disk at Repopath.
+	FS fs.FS
+	// Commit is the hash of the commit analyzed, empty when the files do not
+	// come from a branch of a git repository.
+	Commit string
+	// cached is set when Repopath is a clone of the cache of Params.
+	cached bool
+}
+
+// reportSet is one view of the scan results: the sorter ordering them and the
+// reporters writing them, either by file or by language.
+type reportSet struct {
+	byFile    bool
 	sorter    sorter.Sorter
 	reporters []reporter.Reporter
-	Repopath  string
 }
 
 /* func NewGCloc(params Params, languages language.Languages) (*GCloc, error) {
@@ -141,10 +176,11 @@ type GCloc struct {
 }*/
 
 func NewGCloc(params Params, languages language.Languages) (*GCloc, error) {
-	path, err := getRepoPath(params)
+	path, files, commit, err := getRepoSource(params)
 	if err != nil {
 		return nil, err
 	}
+	cached := usesCache(params)
 
 	if params.Branch == "" {
 		if lastPart := filepath.Base(path); lastPart != "" {
@@ -154,49 +190,143 @@ func NewGCloc(params Params, languages language.Languages) (*GCloc, error) {
 		}
 	}
 
-	excludePaths, err := filesystem.GetExcludePaths(path, params.ExcludePaths)
+	var repoConfig RepoConfig
+	if files != nil {
+		repoConfig, err = loadRepoConfig(files)
+	} else {
+		repoConfig, err = LoadRepoConfig(path)
+	}
+	if err == nil {
+		params, languages, err = repoConfig.merge(params, languages)
+	}
 	if err != nil {
-		return nil, err
+		utils.NewLogger().Errorf("❌ Rules of repository %s ignored: %v", filepath.Base(path), err)
+		repoConfig = RepoConfig{}
 	}
+	rules := repoConfig.appliedRules(params)
 
-	analyzer, scanner, reporters := initAnalyzerScannerReporters(path, params, excludePaths, languages)
+	analyzer, scanner := initAnalyzerScanner(path, params, languages)
+	analyzer.FS = files
 
 	params.Cloned = true
 
 	return &GCloc{
-		Params:    params,
-		analyzer:  analyzer,
-		scanner:   scanner,
-		sorter:    getSorter(params.ByFile, params.Order),
-		reporters: reporters,
-		Repopath:  path,
+		Params:   params,
+		analyzer: analyzer,
+		scanner:  scanner,
+		reports:  getReportSets(params, rules, commit),
+		Repopath: path,
+		Rules:    rules,
+		FS:       files,
+		Commit:   commit,
+		cached:   cached,
 	}, nil
 }
 
+// getRepoSource returns the path of the repository, its files when they are
+// read into memory instead of being on disk at that path, and the commit
+// analyzed.
+func getRepoSource(params Params) (string, fs.FS, string, error) {
+	if params.InMemory && !params.Cloned && len(params.Branch) != 0 {
+		return gogit.GetTree(params.Path, params.Branch, params.Credential)
+	}
+
+	path, err := getRepoPath(params)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return path, nil, repoCommit(params, path), nil
+}
+
+// repoCommit returns the commit checked out at path when a branch is
+// analyzed, empty when it cannot be read.
+func repoCommit(params Params, path string) string {
+	if len(params.Branch) == 0 {
+		return ""
+	}
+	commit, err := gogit.HeadCommit(path)
+	if err != nil {
+		return ""
+	}
+
+	return commit
+}
+
+// usesCache tells whether the repository is checked out from the cache.
+func usesCache(params Params) bool {
+	return params.Cache != nil && !params.Cloned && !params.InMemory && len(params.Branch) != 0
+}
+
 func getRepoPath(params Params) (string, error) {
 	if params.Cloned {
 		return params.Repopath, nil
 	}
 
+	if usesCache(params) {
+		key := params.CacheKey
+		key.Branch = params.Branch
+		return params.Cache.Checkout(key, params.Path, params.Credential)
+	}
 	if len(params.Branch) != 0 {
-		return gogit.Getrepos(params.Path, params.Branch, params.Token)
+		return gogit.Getrepos(params.Path, params.Branch, params.Credential)
 	}
 	return getter.Getter(params.Path)
 }
 
-func initAnalyzerScannerReporters(path string, params Params, excludePaths []string, languages language.Languages) (*analyzer.Analyzer, *scanner.Scanner, []reporter.Reporter) {
+// RemoveRepo removes the clone of the repository analyzed, or gives it back
+// to the cache it was checked out from.
+func (gc *GCloc) RemoveRepo() error {
+	if gc.cached {
+		gc.Params.Cache.Release(gc.Repopath)
+		return nil
+	}
+
+	return os.RemoveAll(gc.Repopath)
+}
+
+func initAnalyzerScanner(path string, params Params, languages language.Languages) (*analyzer.Analyzer, *scanner.Scanner) {
 	analyzer := analyzer.NewAnalyzer(
 		path,
-		excludePaths,
+		params.ExcludePaths,
 		utils.ConvertToMap(params.ExcludeExtensions),
 		utils.ConvertToMap(params.IncludeExtensions),
 		getExtensionsMap(languages),
 	)
+	analyzer.IncludePaths = params.IncludePaths
+	analyzer.MaxFileSize = params.MaxFileSize
+	analyzer.GitExclusions = params.GitExclusions
+	analyzer.FileNames = getFileNamesMap(languages)
+	analyzer.Shebangs = getShebangsMap(languages)
+	analyzer.ExtensionLanguages = getExtensionLanguagesMap(languages)
+	analyzer.TestPatterns = getTestPatternsMap(languages, params.TestPatterns)
 	scanner := scanner.NewScanner(languages)
+	scanner.Workers = params.ScanWorkers
+	scanner.Generated = params.GeneratedCode
+	scanner.MaxLineLength = params.MaxLineLength
 
-	reporters := getReporters(params.ReportFormats, params.OutputName, params.OutputPath, params.ByFile)
+	return analyzer, scanner
+}
+
+// getReportSets returns the by-file or the by-language report set, or both
+// when ByAll is set so that a single walk and scan feed every report. The
+// JSON reports record the rules and the commit of the analysis.
+func getReportSets(params Params, rules *AppliedRules, commit string) []reportSet {
+	views := []bool{params.ByFile}
+	if params.ByAll {
+		views = []bool{true, false}
+	}
+
+	var sets []reportSet
+	for _, byFile := range views {
+		sets = append(sets, reportSet{
+			byFile:    byFile,
+			sorter:    getSorter(byFile, params.Order),
+			reporters: getReporters(params.ReportFormats, params.OutputName, params.OutputPath, byFile, rules, commit),
+		})
+	}
 
-	return analyzer, scanner, reporters
+	return sets
 }
 
 func (gc *GCloc) Run() error {
@@ -206,67 +336,94 @@ func (gc *GCloc) Run() error {
 		return err
 	}
 
-	scanResult, err := gc.scanner.Scan(files)
+	summary, err := gc.scanner.ScanSummary(files, gc.keepsFiles())
 	if err != nil {
 		return err
 	}
+	for reason, count := range gc.analyzer.ExcludedFiles() {
+		summary.ExcludedFiles[reason] += count
+	}
+	summary.SkippedFiles = append(append([]analyzer.SkippedFile{}, gc.analyzer.SkippedFiles()...), summary.SkippedFiles...)
 
-	summary := gc.scanner.Summary(scanResult)
+	for _, set := range gc.reports {
+		if err := set.generateReports(gc.sortSummary(set.sorter, summary)); err != nil {
+			return err
+		}
+	}
 
-	sortedSummary := gc.sortSummary(summary)
+	return nil
+}
+
+func (gc *GCloc) keepsFiles() bool {
+	for _, set := range gc.reports {
+		if set.byFile {
+			return true
+		}
+	}
 
-	return gc.generateReports(sortedSummary)
+	return false
 }
 
+// ChangeLanguages replaces the languages of the analysis, such as the
+// built-in ones merged with user definitions by language.Languages.Merge.
 func (gc *GCloc) ChangeLanguages(languages language.Languages) {
 	extensions := getExtensionsMap(languages)
 	gc.scanner.SupportedLanguages = languages
 	gc.analyzer.SupportedExtensions = extensions
+	gc.analyzer.FileNames = getFileNamesMap(languages)
+	gc.analyzer.Shebangs = getShebangsMap(languages)
+	gc.analyzer.ExtensionLanguages = getExtensionLanguagesMap(languages)
+	gc.analyzer.TestPatterns = getTestPatternsMap(languages, gc.Params.TestPatterns)
 }
 
-func (gc *GCloc) sortSummary(summary *scanner.Summary) *sorter.SortedSummary {
+// Languages returns the languages of the analysis, each with its Origin.
+func (gc *GCloc) Languages() language.Languages {
+	return gc.scanner.SupportedLanguages
+}
+
+func (gc *GCloc) sortSummary(s sorter.Sorter, summary *scanner.Summary) *sorter.SortedSummary {
 	params := gc.Params
 
 	if params.OrderByCode {
-		return gc.sorter.OrderByCodeLines(summary)
+		return s.OrderByCodeLines(summary)
 	}
 
 	if params.OrderByLang {
-		return gc.sorter.OrderByLanguage(summary)
+		return s.OrderByLanguage(summary)
 	}
 
 	if params.OrderByLine {
-		return gc.sorter.OrderByLines(summary)
+		return s.OrderByLines(summary)
 	}
 
 	if params.OrderByComment {
-		return gc.sorter.OrderByComments(summary)
+		return s.OrderByComments(summary)
 	}
 
 	if params.OrderByBlank {
-		return gc.sorter.OrderByBlankLines(summary)
+		return s.OrderByBlankLines(summary)
 	}
 
 	if params.OrderByFile {
-		if languageSorter, ok := gc.sorter.(sorter.LanguageSorter); ok {
+		if languageSorter, ok := s.(sorter.LanguageSorter); ok {
 			return languageSorter.OrderByFiles(summary)
 		}
 	}
 
-	return gc.sorter.OrderByCodeLines(summary)
+	return s.OrderByCodeLines(summary)
 }
 
-func (gc *GCloc) generateReports(sortedSummary *sorter.SortedSummary) error {
+func (set reportSet) generateReports(sortedSummary *sorter.SortedSummary) error {
 
-	if gc.Params.ByFile {
-		for _, reporter := range gc.reporters {
+	if set.byFile {
+		for _, reporter := range set.reporters {
 			if err := reporter.GenerateReportByFile(sortedSummary); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	for _, reporter := range gc.reporters {
+	for _, reporter := range set.reporters {
 		if err := reporter.GenerateReportByLanguage(sortedSummary); err != nil {
 			return err
 		}
@@ -275,18 +432,73 @@ func (gc *GCloc) generateReports(sortedSummary *sorter.SortedSummary) error {
 	return nil
 }
 
+// getExtensionsMap maps each extension to a language. When several
+// languages claim an extension, the first one by name is kept so that the
+// result does not depend on the map order.
 func getExtensionsMap(languages language.Languages) map[string]string {
 	extensions := map[string]string{}
 
-	for language, languageInfo := range languages {
-		for _, extension := range languageInfo.Extensions {
-			extensions[extension] = language
+	for extension, names := range getExtensionLanguagesMap(languages) {
+		extensions[extension] = names[0]
+	}
+
+	return extensions
+}
+
+// getExtensionLanguagesMap lists, by extension, the languages claiming it sorted by name.
+func getExtensionLanguagesMap(languages language.Languages) map[string][]string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	extensions := map[string][]string{}
+	for _, name := range names {
+		for _, extension := range languages[name].Extensions {
+			extensions[extension] = append(extensions[extension], name)
 		}
 	}
 
 	return extensions
 }
 
+func getFileNamesMap(languages language.Languages) map[string]string {
+	fileNames := map[string]string{}
+
+	for language, languageInfo := range languages {
+		for _, fileName := range languageInfo.FileNames {
+			fileNames[fileName] = language
+		}
+	}
+
+	return fileNames
+}
+
+func getShebangsMap(languages language.Languages) map[string]string {
+	shebangs := map[string]string{}
+
+	for language, languageInfo := range languages {
+		for _, interpreter := range languageInfo.Shebangs {
+			shebangs[interpreter] = language
+		}
+	}
+
+	return shebangs
+}
+
+// getTestPatternsMap returns, by language, its default test patterns
+// followed by the ones the user configured for every language.
+func getTestPatternsMap(languages language.Languages, userPatterns []string) map[string][]string {
+	patterns := map[string][]string{}
+
+	for language, languageInfo := range languages {
+		patterns[language] = append(append([]string{}, languageInfo.TestPatterns...), userPatterns...)
+	}
+
+	return patterns
+}
+
 func getSorter(byFile bool, order string) sorter.Sorter {
 	if byFile {
 		return sorter.NewFileSorter(order)
@@ -295,10 +507,16 @@ func getSorter(byFile bool, order string) sorter.Sorter {
 	return sorter.NewLanguageSorter(order)
 }
 
-func getReporters(reportFormats []string, outputName, outputPath string, byfile bool) []reporter.Reporter {
+func getReporters(reportFormats []string, outputName, outputPath string, byfile bool, rules *AppliedRules, commit string) []reporter.Reporter {
 	var reporters []reporter.Reporter
 	indicemode := "_byfile"
 
+	// A nil *AppliedRules would not be left out of the JSON reports.
+	var jsonRules interface{}
+	if rules != nil {
+		jsonRules = rules
+	}
+
 	for _, format := range reportFormats {
 		switch format {
 		case "prompt":
@@ -312,6 +530,8 @@ func getReporters(reportFormats []string, outputName, outputPath string, byfile
 				reporters = append(reporters, json.JsonReporter{
 					OutputName: outputName + indicemode,
 					OutputPath: outputPath + typereportPath,
+					Rules:      jsonRules,
+					Commit:     commit,
 				})
 
 				reporters = append(reporters, csv.CsvReporter{
@@ -337,6 +557,8 @@ func getReporters(reportFormats []string, outputName, outputPath string, byfile
 				reporters = append(reporters, json.JsonReporter{
 					OutputName: outputName,
 					OutputPath: outputPath + typereportPath,
+					Rules:      jsonRules,
+					Commit:     commit,
 				})
 			}
 
@@ -0,0 +1,141 @@
+package goloc
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/gogit"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitRepo commits the files in a new repository and returns its path.
+func commitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeRepoFiles(t, root, files)
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range files {
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature := &object.Signature{Name: "golc", Email: "golc@example.com", When: time.Now()}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func TestNewGClocInMemory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+
+	root := commitRepo(t, map[string]string{
+		".golc.yml":       "excludePaths: [legacy]\n",
+		"main.go":         "package main\n\n// entry point\nfunc main() {}\n",
+		"legacy/old.go":   "package legacy\n",
+		"internal/lib.go": "package internal\nvar x = 1\n",
+	})
+
+	output := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(output, "bylanguage-report"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	params := Params{
+		Path:          root,
+		Branch:        "master",
+		InMemory:      true,
+		OutputName:    "Result_repo",
+		OutputPath:    output,
+		ReportFormats: []string{"json"},
+		Order:         "DESC",
+	}
+	languages := language.Languages{
+		"Golang": {LineComments: []string{"//"}, Extensions: []string{".go"}},
+	}
+
+	gc, err := NewGCloc(params, languages)
+	if err != nil {
+		t.Fatalf("NewGCloc error: %v", err)
+	}
+	if gc.FS == nil {
+		t.Fatal("the repository should be read into memory")
+	}
+	if _, err := os.Stat(gc.Repopath); !os.IsNotExist(err) {
+		t.Errorf("nothing should be checked out at %s", gc.Repopath)
+	}
+	if err := gc.Run(); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "bylanguage-report", "Result_repo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		TotalFiles     int
+		TotalCodeLines int
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	// main.go and internal/lib.go, legacy being excluded by .golc.yml.
+	if result.TotalFiles != 2 || result.TotalCodeLines != 4 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+}
+
+func TestNewGClocFromCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+	root := commitRepo(t, map[string]string{"main.go": "package main\n"})
+	cache, err := gogit.NewCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := Params{
+		Path:          root,
+		Branch:        "master",
+		Cache:         cache,
+		CacheKey:      gogit.CacheKey{Platform: "github", Repository: "org/repo"},
+		OutputName:    "Result_repo",
+		OutputPath:    t.TempDir(),
+		ReportFormats: []string{"json"},
+		Order:         "DESC",
+	}
+	languages := language.Languages{
+		"Golang": {LineComments: []string{"//"}, Extensions: []string{".go"}},
+	}
+
+	for run := 0; run < 2; run++ {
+		gc, err := NewGCloc(params, languages)
+		if err != nil {
+			t.Fatalf("NewGCloc error: %v", err)
+		}
+		if filepath.Dir(filepath.Dir(gc.Repopath)) != cache.Dir {
+			t.Errorf("the repository should be cloned in the cache, not at %s", gc.Repopath)
+		}
+		if err := gc.RemoveRepo(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(gc.Repopath, "main.go")); err != nil {
+			t.Errorf("the clone should be kept in the cache: %v", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package language
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltIn is the origin of the languages compiled into golc.
+const BuiltIn = "built-in"
+
+// Definitions are user language definitions by name, each one a JSON object
+// with the fields of LanguageInfo and an optional "Disabled" flag.
+type Definitions map[string]json.RawMessage
+
+// definition is the schema of an entry of Definitions.
+type definition struct {
+	LanguageInfo
+	Disabled bool
+}
+
+// ReadDefinitions reads the language definitions of a JSON file, or of a
+// YAML file when its extension is .yaml or .yml.
+func ReadDefinitions(path string) (Definitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ failed to read languages file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var entries map[string]interface{}
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("❌ failed to parse languages YAML %s: %v", path, err)
+		}
+
+		definitions := Definitions{}
+		for name, entry := range entries {
+			raw, err := json.Marshal(entry)
+			if err != nil {
+				return nil, fmt.Errorf("❌ invalid definition of language %q in %s: %v", name, path, err)
+			}
+			definitions[name] = raw
+		}
+		return definitions, nil
+	default:
+		var definitions Definitions
+		if err := json.Unmarshal(data, &definitions); err != nil {
+			return nil, fmt.Errorf("❌ failed to parse languages JSON %s: %v", path, err)
+		}
+		return definitions, nil
+	}
+}
+
+// Merge returns a copy of the languages with the definitions applied over
+// them: a definition naming a known language overrides only the fields it
+// sets, one naming a new language adds it, and one with "Disabled" removes
+// the language. The languages added or changed get origin as Origin, the
+// others keep theirs or get BuiltIn.
+func (languages Languages) Merge(definitions Definitions, origin string) (Languages, error) {
+	merged := make(Languages, len(languages)+len(definitions))
+	for name, info := range languages {
+		if info.Origin == "" {
+			info.Origin = BuiltIn
+		}
+		merged[name] = info
+	}
+
+	// Names are merged in order so that the errors do not depend on the map order.
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		current, known := merged[name]
+		entry := definition{LanguageInfo: current.clone()}
+
+		decoder := json.NewDecoder(bytes.NewReader(definitions[name]))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("❌ invalid definition of language %q in %s: %v", name, origin, err)
+		}
+
+		if entry.Disabled {
+			delete(merged, name)
+			continue
+		}
+		if !known && len(entry.Extensions) == 0 && len(entry.FileNames) == 0 && len(entry.Shebangs) == 0 {
+			return nil, fmt.Errorf("❌ language %q in %s has no Extensions, FileNames or Shebangs", name, origin)
+		}
+
+		entry.Origin = origin
+		merged[name] = entry.LanguageInfo
+	}
+
+	return merged, nil
+}
+
+// clone copies the slices of the language, which decoding a definition
+// over it would otherwise overwrite in place.
+func (info LanguageInfo) clone() LanguageInfo {
+	info.LineComments = append([]string(nil), info.LineComments...)
+	info.MultiLineComments = cloneTokens(info.MultiLineComments)
+	info.Extensions = append([]string(nil), info.Extensions...)
+	info.FileNames = append([]string(nil), info.FileNames...)
+	info.Shebangs = append([]string(nil), info.Shebangs...)
+	info.StringDelimiters = append([]string(nil), info.StringDelimiters...)
+	info.MultiLineStrings = cloneTokens(info.MultiLineStrings)
+	info.DocStringScopes = append([]string(nil), info.DocStringScopes...)
+	info.TestPatterns = append([]string(nil), info.TestPatterns...)
+
+	return info
+}
+
+func cloneTokens(tokens [][]string) [][]string {
+	if tokens == nil {
+		return nil
+	}
+
+	cloned := make([][]string, len(tokens))
+	for i, pair := range tokens {
+		cloned[i] = append([]string(nil), pair...)
+	}
+
+	return cloned
+}
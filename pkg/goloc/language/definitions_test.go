@@ -0,0 +1,74 @@
+package language
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeDefinitions(t *testing.T) {
+	builtIn := Languages{
+		"SQL": {
+			LineComments:      []string{"--"},
+			MultiLineComments: [][]string{{"/*", "*/"}},
+			Extensions:        []string{".sql"},
+		},
+		"Vue": {Extensions: []string{".vue"}},
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "languages.yaml")
+	yamlDefinitions := `
+SQL:
+  Extensions: [".sql", ".pks"]
+Vue:
+  Disabled: true
+Pipeline:
+  LineComments: ["#"]
+  FileNames: ["*.pipeline"]
+`
+	if err := os.WriteFile(path, []byte(yamlDefinitions), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions, err := ReadDefinitions(path)
+	if err != nil {
+		t.Fatalf("ReadDefinitions: %v", err)
+	}
+	merged, err := builtIn.Merge(definitions, "languages.yaml")
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	sql := merged["SQL"]
+	if !reflect.DeepEqual(sql.Extensions, []string{".sql", ".pks"}) || sql.LineComments[0] != "--" || sql.Origin != "languages.yaml" {
+		t.Errorf("SQL = %+v, want the built-in comments, the new extensions and the file as origin", sql)
+	}
+	if !reflect.DeepEqual(builtIn["SQL"].Extensions, []string{".sql"}) {
+		t.Errorf("built-in SQL extensions changed to %v", builtIn["SQL"].Extensions)
+	}
+	if _, ok := merged["Vue"]; ok {
+		t.Error("Vue should be disabled")
+	}
+	if pipeline := merged["Pipeline"]; pipeline.FileNames[0] != "*.pipeline" || pipeline.Origin != "languages.yaml" {
+		t.Errorf("Pipeline = %+v, want the added language", pipeline)
+	}
+
+	merged, err = merged.Merge(Definitions{"SQL": []byte(`{"LineComments": ["--", "#"]}`)}, "config.json")
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if sql := merged["SQL"]; len(sql.LineComments) != 2 || len(sql.Extensions) != 2 || sql.Origin != "config.json" {
+		t.Errorf("SQL = %+v, want both overrides applied", sql)
+	}
+
+	for name, definition := range map[string]string{
+		"unknown field":     `{"Extension": [".x"]}`,
+		"no file selection": `{"LineComments": ["#"]}`,
+	} {
+		if _, err := builtIn.Merge(Definitions{"New": []byte(definition)}, "config.json"); err == nil {
+			t.Errorf("%s: Merge should fail", name)
+		}
+	}
+}
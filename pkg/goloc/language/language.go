@@ -5,6 +5,54 @@ type LanguageInfo struct {
 	LineComments      []string
 	MultiLineComments [][]string
 	Extensions        []string
+	// FileNames are the patterns of the file names of the language, such as
+	// "Dockerfile.*", for the files its extensions do not cover.
+	FileNames []string
+	// Shebangs are the interpreters which, named on the "#!" line of a file
+	// without extension, give the language, as in "#!/usr/bin/env python".
+	Shebangs []string
+	// NestedComments is set when a block comment can hold other block comments.
+	NestedComments bool
+	// StringDelimiters are the quotes of the string literals closed on the
+	// same line; " is used when empty.
+	StringDelimiters []string
+	// MultiLineStrings are the opening and closing tokens of the string
+	// literals that can span several lines, followed by RawString when a
+	// backslash escapes nothing in them, as in the raw strings of Go.
+	MultiLineStrings [][]string
+	// DocStringScopes makes a string standing as the first statement of the
+	// file, or of a block opened by a line starting with one of these
+	// keywords, a docstring counted as a comment.
+	DocStringScopes []string
+	// TestPatterns mark the files holding test code: a pattern ending with
+	// "/" matches a directory at any depth, any other pattern a file name.
+	TestPatterns []string
+	// Notebook marks the Jupyter notebooks, whose code cells are counted
+	// with the rules of the language of their kernel.
+	Notebook bool
+	// SonarKey is the key of the language in SonarQube, empty when
+	// SonarQube does not analyze it.
+	SonarKey string
+	// Edition is the first SonarQube edition analyzing the language.
+	Edition string
+	// Origin is where the definition comes from: BuiltIn, or the file
+	// whose definitions added or changed it.
+	Origin string
 }
 
 type Languages map[string]LanguageInfo
+
+// RawString marks the delimiters of MultiLineStrings whose string literals
+// have no escape sequences.
+const RawString = "raw"
+
+// NotebookSuffix follows the language of the kernel in the results of the
+// notebooks, as in "Python (notebook)".
+const NotebookSuffix = " (notebook)"
+
+// The SonarQube editions, each one analyzing the languages of the previous ones.
+const (
+	EditionCommunity  = "Community"
+	EditionDeveloper  = "Developer"
+	EditionEnterprise = "Enterprise"
+)
@@ -0,0 +1,186 @@
+package goloc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Files a repository can check in at its root to declare its own rules.
+const (
+	RepoConfigFile = ".golc.yml"
+	RepoIgnoreFile = ".golcignore"
+)
+
+// repoConfigFiles are the names accepted for RepoConfigFile.
+var repoConfigFiles = []string{RepoConfigFile, ".golc.yaml"}
+
+// RepoConfig are the rules of a repository, read from RepoConfigFile with
+// the names of the platform settings of config.json, and from the path
+// patterns of RepoIgnoreFile, one per line, added to ExcludePaths.
+type RepoConfig struct {
+	IncludePaths []string
+	ExcludePaths []string
+	ExtExclusion []string
+	TestPatterns []string
+	Languages    language.Definitions
+	// Files are the files the rules were read from.
+	Files []string `json:"-"`
+}
+
+// AppliedRules are the rules of an analysis, the central ones merged with
+// those of the repository, recorded in its JSON reports.
+type AppliedRules struct {
+	// RepoFiles are the files of the repository its rules were read from.
+	RepoFiles         []string `json:",omitempty"`
+	IncludePaths      []string `json:",omitempty"`
+	ExcludePaths      []string `json:",omitempty"`
+	ExcludeExtensions []string `json:",omitempty"`
+	TestPatterns      []string `json:",omitempty"`
+	// Languages are the languages the repository added, changed or disabled.
+	Languages []string `json:",omitempty"`
+}
+
+// LoadRepoConfig reads the rules checked in at the root of the repository
+// at path. It returns an empty configuration when there are none.
+func LoadRepoConfig(path string) (RepoConfig, error) {
+	return loadRepoConfig(os.DirFS(path))
+}
+
+// loadRepoConfig reads the rules checked in at the root of the files of a
+// repository.
+func loadRepoConfig(files fs.FS) (RepoConfig, error) {
+	var config RepoConfig
+
+	for _, name := range repoConfigFiles {
+		data, err := fs.ReadFile(files, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return RepoConfig{}, err
+		}
+		if err := parseRepoConfig(data, &config); err != nil {
+			return RepoConfig{}, fmt.Errorf("❌ invalid %s: %v", name, err)
+		}
+		config.Files = append(config.Files, name)
+		break
+	}
+
+	patterns, err := readIgnoreFile(files, RepoIgnoreFile)
+	if err != nil {
+		return RepoConfig{}, err
+	}
+	if patterns != nil {
+		config.ExcludePaths = append(config.ExcludePaths, patterns...)
+		config.Files = append(config.Files, RepoIgnoreFile)
+	}
+
+	return config, nil
+}
+
+// parseRepoConfig decodes the YAML rules through JSON, whose field names
+// are not case sensitive.
+func parseRepoConfig(data []byte, config *RepoConfig) error {
+	var entries map[string]interface{}
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	if entries == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(config)
+}
+
+// readIgnoreFile returns the path patterns of an ignore file, skipping
+// blank lines and "#" comments, or nil when there is no such file.
+func readIgnoreFile(files fs.FS, name string) ([]string, error) {
+	file, err := files.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// merge adds the rules of the repository after the central ones of params,
+// so that a negated path pattern of the repository can keep a file the
+// central patterns exclude, and applies its language definitions.
+func (c RepoConfig) merge(params Params, languages language.Languages) (Params, language.Languages, error) {
+	if len(c.Languages) > 0 {
+		merged, err := languages.Merge(c.Languages, RepoConfigFile)
+		if err != nil {
+			return params, languages, err
+		}
+		languages = merged
+	}
+
+	params.IncludePaths = appendRules(params.IncludePaths, c.IncludePaths)
+	params.ExcludePaths = appendRules(params.ExcludePaths, c.ExcludePaths)
+	params.ExcludeExtensions = appendRules(params.ExcludeExtensions, c.ExtExclusion)
+	params.TestPatterns = appendRules(params.TestPatterns, c.TestPatterns)
+
+	return params, languages, nil
+}
+
+// appendRules returns a new slice, since the central rules are shared by
+// the analyses of every repository.
+func appendRules(central, repo []string) []string {
+	if len(repo) == 0 {
+		return central
+	}
+
+	return append(append([]string{}, central...), repo...)
+}
+
+// appliedRules returns the rules of the analysis, or nil when it has none.
+func (c RepoConfig) appliedRules(params Params) *AppliedRules {
+	rules := &AppliedRules{
+		RepoFiles:         c.Files,
+		IncludePaths:      params.IncludePaths,
+		ExcludePaths:      params.ExcludePaths,
+		ExcludeExtensions: params.ExcludeExtensions,
+		TestPatterns:      params.TestPatterns,
+	}
+	for name := range c.Languages {
+		rules.Languages = append(rules.Languages, name)
+	}
+	sort.Strings(rules.Languages)
+
+	if len(rules.RepoFiles)+len(rules.IncludePaths)+len(rules.ExcludePaths)+len(rules.ExcludeExtensions)+len(rules.TestPatterns) == 0 {
+		return nil
+	}
+
+	return rules
+}
@@ -0,0 +1,121 @@
+package goloc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+func writeRepoFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestNewGClocAppliesRepoConfig(t *testing.T) {
+	root := t.TempDir()
+	writeRepoFiles(t, root, map[string]string{
+		".golc.yml": `
+excludePaths: ["!legacy/keep.go"]
+extExclusion: [".js"]
+testPatterns: ["e2e/"]
+languages:
+  Golang:
+    LineComments: ["//", "#"]
+`,
+		".golcignore":    "# generated code\n**/gen/**\n\n",
+		"main.go":        "package main\n# not code\n",
+		"gen/model.go":   "package gen\n",
+		"legacy/old.go":  "package legacy\n",
+		"legacy/keep.go": "package legacy\n",
+		"e2e/flow.go":    "package e2e\n",
+		"web/app.js":     "var a = 1;\n",
+	})
+	output := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(output, "bylanguage-report"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	languages := language.Languages{
+		"Golang":     {LineComments: []string{"//"}, Extensions: []string{".go"}},
+		"JavaScript": {LineComments: []string{"//"}, Extensions: []string{".js"}},
+	}
+	params := Params{
+		Path:          root,
+		Repopath:      root,
+		Cloned:        true,
+		ExcludePaths:  []string{"legacy"},
+		OutputName:    "Result_repo",
+		OutputPath:    output,
+		ReportFormats: []string{"json"},
+		Order:         "DESC",
+		Branch:        "main",
+	}
+
+	gc, err := NewGCloc(params, languages)
+	if err != nil {
+		t.Fatalf("NewGCloc error: %v", err)
+	}
+	want := &AppliedRules{
+		RepoFiles:         []string{".golc.yml", ".golcignore"},
+		ExcludePaths:      []string{"legacy", "!legacy/keep.go", "**/gen/**"},
+		ExcludeExtensions: []string{".js"},
+		TestPatterns:      []string{"e2e/"},
+		Languages:         []string{"Golang"},
+	}
+	if !reflect.DeepEqual(gc.Rules, want) {
+		t.Errorf("Rules = %+v, want %+v", gc.Rules, want)
+	}
+	if len(params.ExcludePaths) != 1 {
+		t.Errorf("the central rules should not change: %v", params.ExcludePaths)
+	}
+
+	if err := gc.Run(); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(output, "bylanguage-report", "Result_repo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		TotalCodeLines     int
+		TotalTestCodeLines int
+		Rules              AppliedRules
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	// main.go, legacy/keep.go and e2e/flow.go, the "#" line being a comment.
+	if result.TotalCodeLines != 3 || result.TotalTestCodeLines != 1 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+	if !reflect.DeepEqual(result.Rules, *want) {
+		t.Errorf("recorded rules = %+v, want %+v", result.Rules, *want)
+	}
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	root := t.TempDir()
+	if config, err := LoadRepoConfig(root); err != nil || config.Files != nil {
+		t.Errorf("LoadRepoConfig without files = %+v, %v", config, err)
+	}
+	if rules := (RepoConfig{}).appliedRules(Params{}); rules != nil {
+		t.Errorf("appliedRules without rules = %+v, want nil", rules)
+	}
+
+	writeRepoFiles(t, root, map[string]string{".golc.yaml": "ExcludePath: [vendor]\n"})
+	if _, err := LoadRepoConfig(root); err == nil {
+		t.Error("LoadRepoConfig should fail on an unknown setting")
+	}
+}
@@ -0,0 +1,180 @@
+package goloc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+// resultKeyFile holds the commit and the configuration hash the reports of
+// an entry of a ResultCache were produced from.
+const resultKeyFile = "golc-result-key"
+
+// ResultCache keeps the reports of the branches analyzed in a directory
+// across runs, with the commit and the configuration they were produced
+// from, so that a branch whose last commit was already analyzed with the
+// same configuration is not cloned and analyzed again.
+type ResultCache struct {
+	Dir string
+}
+
+// ConfigHash returns the hash of the configuration the reports of an
+// analysis depend on: the version of GoLC, the central rules, the reports
+// produced and the definitions of the languages. The rules of a repository
+// are part of its commit.
+func ConfigHash(version string, params Params, languages language.Languages) (string, error) {
+	data, err := json.Marshal(struct {
+		Version           string
+		ExcludePaths      []string
+		IncludePaths      []string
+		ExcludeExtensions []string
+		IncludeExtensions []string
+		TestPatterns      []string
+		GitExclusions     bool
+		GeneratedCode     string
+		MaxFileSize       int64
+		MaxLineLength     int
+		ByFile            bool
+		ByAll             bool
+		Order             string
+		ReportFormats     []string
+		Languages         language.Languages
+	}{
+		version, params.ExcludePaths, params.IncludePaths, params.ExcludeExtensions, params.IncludeExtensions,
+		params.TestPatterns, params.GitExclusions, params.GeneratedCode, params.MaxFileSize, params.MaxLineLength,
+		params.ByFile, params.ByAll, params.Order, params.ReportFormats, languages,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Restore copies the reports of params kept for the commit and the
+// configuration hash into their output path, and tells whether there were
+// any.
+func (c ResultCache) Restore(params Params, commit, configHash string) (bool, error) {
+	dir := c.entryDir(params)
+	key, err := os.ReadFile(filepath.Join(dir, resultKeyFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if commit == "" || string(key) != resultKey(commit, configHash) {
+		return false, nil
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() == resultKeyFile {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		return copyFile(path, filepath.Join(params.OutputPath, rel))
+	})
+	if err != nil {
+		return false, fmt.Errorf("❌ failed to restore the reports of %s: %v", params.OutputName, err)
+	}
+
+	return true, nil
+}
+
+// Store keeps the reports of params, produced from the commit with the
+// configuration hash, in place of the ones kept before.
+func (c ResultCache) Store(params Params, commit, configHash string) error {
+	if commit == "" {
+		return nil
+	}
+
+	dir := c.entryDir(params)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for _, rel := range reportFiles(params) {
+		err := copyFile(filepath.Join(params.OutputPath, rel), filepath.Join(dir, rel))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("❌ failed to keep the reports of %s: %v", params.OutputName, err)
+		}
+	}
+
+	// The key is written last, so that the reports of an entry are complete.
+	return os.WriteFile(filepath.Join(dir, resultKeyFile), []byte(resultKey(commit, configHash)), 0644)
+}
+
+func (c ResultCache) entryDir(params Params) string {
+	return filepath.Join(c.Dir, strings.Replace(params.OutputName, "/", "_", -1))
+}
+
+func resultKey(commit, configHash string) string {
+	return commit + "\n" + configHash + "\n"
+}
+
+// reportFiles returns the paths, relative to the output path, of the report
+// files of params, as named by getReporters.
+func reportFiles(params Params) []string {
+	name := strings.Replace(params.OutputName, "/", "_", -1)
+	views := []bool{params.ByFile}
+	if params.ByAll {
+		views = []bool{true, false}
+	}
+
+	var files []string
+	for _, format := range params.ReportFormats {
+		if format != "json" {
+			continue
+		}
+		for _, byFile := range views {
+			if byFile {
+				files = append(files,
+					filepath.Join("byfile-report", name+"_byfile.json"),
+					filepath.Join("byfile-report", "csv-report", name+"_byfile.csv"),
+					filepath.Join("byfile-report", "pdf-report", name+"_byfile.pdf"))
+			} else {
+				files = append(files, filepath.Join("bylanguage-report", name+".json"))
+			}
+		}
+	}
+
+	return files
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
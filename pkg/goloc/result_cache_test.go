@@ -0,0 +1,113 @@
+package goloc
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/gogit"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+func TestResultCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("cloning a local repository needs git-upload-pack")
+	}
+	root := commitRepo(t, map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	output := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(output, "bylanguage-report"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	params := Params{
+		Path:          root,
+		Branch:        "master",
+		OutputName:    "Result_org_repo_master",
+		OutputPath:    output,
+		ReportFormats: []string{"json"},
+		Order:         "DESC",
+	}
+	languages := language.Languages{
+		"Golang": {LineComments: []string{"//"}, Extensions: []string{".go"}},
+	}
+	hash, err := ConfigHash("1.0.0", params, languages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := gogit.RemoteCommit(root, "master", gogit.Credential{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc, err := NewGCloc(params, languages)
+	if err != nil {
+		t.Fatalf("NewGCloc error: %v", err)
+	}
+	defer gc.RemoveRepo()
+	if gc.Commit != commit {
+		t.Errorf("Commit = %s, want %s", gc.Commit, commit)
+	}
+	if err := gc.Run(); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	report := filepath.Join("bylanguage-report", "Result_org_repo_master.json")
+	data, err := os.ReadFile(filepath.Join(output, report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct{ Commit string }
+	if err := json.Unmarshal(data, &result); err != nil || result.Commit != commit {
+		t.Errorf("the report should record the commit %s: %+v, %v", commit, result, err)
+	}
+
+	cache := ResultCache{Dir: t.TempDir()}
+	if ok, err := cache.Restore(params, commit, hash); ok || err != nil {
+		t.Errorf("Restore from an empty cache = %v, %v", ok, err)
+	}
+	if err := cache.Store(params, commit, hash); err != nil {
+		t.Fatalf("Store error: %v", err)
+	}
+
+	params.OutputPath = t.TempDir()
+	if ok, err := cache.Restore(params, "0123456789abcdef", hash); ok || err != nil {
+		t.Errorf("Restore of another commit = %v, %v", ok, err)
+	}
+	if ok, err := cache.Restore(params, commit, "other"); ok || err != nil {
+		t.Errorf("Restore with another configuration = %v, %v", ok, err)
+	}
+	if ok, err := cache.Restore(params, commit, hash); !ok || err != nil {
+		t.Fatalf("Restore = %v, %v", ok, err)
+	}
+	if restored, err := os.ReadFile(filepath.Join(params.OutputPath, report)); err != nil || string(restored) != string(data) {
+		t.Errorf("restored report = %q, %v", restored, err)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	params := Params{ExcludePaths: []string{"vendor"}, ReportFormats: []string{"json"}}
+	languages := language.Languages{"Golang": {Extensions: []string{".go"}}}
+	hash, err := ConfigHash("1.0.0", params, languages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The clone settings do not change the reports.
+	same := params
+	same.InMemory, same.Branch = true, "main"
+	if other, _ := ConfigHash("1.0.0", same, languages); other != hash {
+		t.Error("the hash should only depend on the settings of the reports")
+	}
+	excluded := params
+	excluded.ExcludePaths = []string{"vendor", "legacy"}
+	if other, _ := ConfigHash("1.0.0", excluded, languages); other == hash {
+		t.Error("the hash should change with the exclusions")
+	}
+	changed := language.Languages{"Golang": {Extensions: []string{".go"}, LineComments: []string{"//"}}}
+	if other, _ := ConfigHash("1.0.0", params, changed); other == hash {
+		t.Error("the hash should change with the language definitions")
+	}
+	if other, _ := ConfigHash("1.0.1", params, languages); other == hash {
+		t.Error("the hash should change with the version")
+	}
+}
@@ -17,12 +17,13 @@ type CsvReporter struct {
 }
 
 type languageResult struct {
-	Language   string
-	Files      int
-	Lines      int
-	BlankLines int
-	Comments   int
-	CodeLines  int
+	Language      string
+	Files         int
+	Lines         int
+	BlankLines    int
+	Comments      int
+	CodeLines     int
+	TestCodeLines int
 }
 
 type fileResult struct {
@@ -31,6 +32,9 @@ type fileResult struct {
 	BlankLines int
 	Comments   int
 	CodeLines  int
+	Kind       string
+	Generated  bool
+	Encoding   string
 }
 
 type report struct {
@@ -54,12 +58,13 @@ func (c CsvReporter) GenerateReportByLanguage(summary *sorter.SortedSummary) err
 
 	for _, r := range summary.Results {
 		csvReport.Results = append(csvReport.Results.([]languageResult), languageResult{
-			Language:   r.Name,
-			Files:      summary.FilesByLanguage[r.Name],
-			Lines:      r.Lines,
-			BlankLines: r.BlankLines,
-			Comments:   r.Comments,
-			CodeLines:  r.CodeLines,
+			Language:      r.Name,
+			Files:         summary.FilesByLanguage[r.Name],
+			Lines:         r.Lines,
+			BlankLines:    r.BlankLines,
+			Comments:      r.Comments,
+			CodeLines:     r.CodeLines,
+			TestCodeLines: r.TestCodeLines,
 		})
 	}
 
@@ -83,6 +88,9 @@ func (c CsvReporter) GenerateReportByFile(summary *sorter.SortedSummary) error {
 			BlankLines: r.BlankLines,
 			Comments:   r.Comments,
 			CodeLines:  r.CodeLines,
+			Kind:       r.Kind,
+			Generated:  r.Generated,
+			Encoding:   r.Encoding,
 		})
 	}
 
@@ -106,13 +114,10 @@ func (c CsvReporter) writeCsv(csvReport *report) error {
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	// Write header
-	writer.Write([]string{"File", "Lines", "Blank Lines", "Comments", "Code Lines"})
-
-	// Write results
+	// Write the header and the results
 	switch results := csvReport.Results.(type) {
 	case []languageResult:
-		//	writer.Write([]string{"Language", "Files", "Lines", "Blank Lines", "Comments", "Code Lines"})
+		writer.Write([]string{"Language", "Files", "Lines", "Blank Lines", "Comments", "Code Lines", "Main Code Lines", "Test Code Lines"})
 		for _, r := range results {
 			writer.Write([]string{
 				r.Language,
@@ -121,10 +126,12 @@ func (c CsvReporter) writeCsv(csvReport *report) error {
 				strconv.Itoa(r.BlankLines),
 				strconv.Itoa(r.Comments),
 				strconv.Itoa(r.CodeLines),
+				strconv.Itoa(r.CodeLines - r.TestCodeLines),
+				strconv.Itoa(r.TestCodeLines),
 			})
 		}
 	case []fileResult:
-		//writer.Write([]string{"File", "Lines", "Blank Lines", "Comments", "Code Lines"})
+		writer.Write([]string{"File", "Lines", "Blank Lines", "Comments", "Code Lines", "Kind", "Generated", "Encoding"})
 		for _, r := range results {
 			writer.Write([]string{
 				r.File,
@@ -132,6 +139,9 @@ func (c CsvReporter) writeCsv(csvReport *report) error {
 				strconv.Itoa(r.BlankLines),
 				strconv.Itoa(r.Comments),
 				strconv.Itoa(r.CodeLines),
+				r.Kind,
+				strconv.FormatBool(r.Generated),
+				r.Encoding,
 			})
 		}
 	default:
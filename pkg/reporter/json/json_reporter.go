@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/SonarSource-Demos/sonar-golc/pkg/scanner"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/sorter"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/utils"
 )
@@ -13,6 +14,10 @@ import (
 type JsonReporter struct {
 	OutputName string
 	OutputPath string
+	// Rules are the rules applied to the analysis, recorded in the report.
+	Rules interface{}
+	// Commit is the hash of the commit analyzed, recorded in the report.
+	Commit string
 }
 
 type languageResult struct {
@@ -22,6 +27,9 @@ type languageResult struct {
 	BlankLines int
 	Comments   int
 	CodeLines  int
+	// MainCodeLines and TestCodeLines split CodeLines between main and test code.
+	MainCodeLines int
+	TestCodeLines int
 }
 
 type fileResult struct {
@@ -30,35 +38,78 @@ type fileResult struct {
 	BlankLines int
 	Comments   int
 	CodeLines  int
+	Kind       string         `json:",omitempty"`
+	Generated  bool           `json:",omitempty"`
+	Regions    []regionResult `json:",omitempty"`
+	Encoding   string         `json:",omitempty"`
+}
+
+// skippedFile is a file not counted, and the reason why.
+type skippedFile struct {
+	File   string
+	Reason string
+}
+
+// regionResult are the lines of a file in one of the languages embedded in it.
+type regionResult struct {
+	Language   string
+	Lines      int
+	BlankLines int
+	Comments   int
+	CodeLines  int
+}
+
+type generatedResult struct {
+	Files      int
+	Lines      int
+	BlankLines int
+	Comments   int
+	CodeLines  int
 }
 
 type report struct {
-	TotalFiles      int `json:",omitempty"`
-	TotalLines      int
-	TotalBlankLines int
-	TotalComments   int
-	TotalCodeLines  int
-	Results         interface{}
+	TotalFiles         int `json:",omitempty"`
+	TotalLines         int
+	TotalBlankLines    int
+	TotalComments      int
+	TotalCodeLines     int
+	TotalMainCodeLines int
+	TotalTestCodeLines int
+	Generated          *generatedResult `json:",omitempty"`
+	ExcludedFiles      map[string]int   `json:",omitempty"`
+	SkippedFiles       []skippedFile    `json:"skipped_files,omitempty"`
+	Commit             string           `json:",omitempty"`
+	Rules              interface{}      `json:",omitempty"`
+	Results            interface{}
 }
 
 func (j JsonReporter) GenerateReportByLanguage(summary *sorter.SortedSummary) error {
 	jsonReport := &report{
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-		TotalCodeLines:  summary.TotalCodeLines,
-		Results:         []languageResult{},
+		TotalFiles:         summary.TotalFiles,
+		TotalLines:         summary.TotalLines,
+		TotalBlankLines:    summary.TotalBlankLines,
+		TotalComments:      summary.TotalComments,
+		TotalCodeLines:     summary.TotalCodeLines,
+		TotalMainCodeLines: summary.TotalCodeLines - summary.TotalTestCodeLines,
+		TotalTestCodeLines: summary.TotalTestCodeLines,
+		Generated:          newGeneratedResult(summary),
+		ExcludedFiles:      summary.ExcludedFiles,
+		SkippedFiles:       newSkippedFiles(summary),
+		Commit:             j.Commit,
+		Rules:              j.Rules,
+		Results:            []languageResult{},
 	}
 
 	for _, r := range summary.Results {
 		jsonReport.Results = append(jsonReport.Results.([]languageResult), languageResult{
-			Language:   r.Name,
-			Files:      summary.FilesByLanguage[r.Name],
-			Lines:      r.Lines,
-			BlankLines: r.BlankLines,
-			Comments:   r.Comments,
-			CodeLines:  r.CodeLines,
+			Language:      r.Name,
+			Files:         summary.FilesByLanguage[r.Name],
+			Lines:         r.Lines,
+			BlankLines:    r.BlankLines,
+			Comments:      r.Comments,
+			CodeLines:     r.CodeLines,
+			MainCodeLines: r.CodeLines - r.TestCodeLines,
+			TestCodeLines: r.TestCodeLines,
 		})
 	}
 
@@ -67,11 +118,18 @@ func (j JsonReporter) GenerateReportByLanguage(summary *sorter.SortedSummary) er
 
 func (j JsonReporter) GenerateReportByFile(summary *sorter.SortedSummary) error {
 	jsonReport := &report{
-		TotalLines:      summary.TotalLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-		TotalCodeLines:  summary.TotalCodeLines,
-		Results:         []fileResult{},
+		TotalLines:         summary.TotalLines,
+		TotalBlankLines:    summary.TotalBlankLines,
+		TotalComments:      summary.TotalComments,
+		TotalCodeLines:     summary.TotalCodeLines,
+		TotalMainCodeLines: summary.TotalCodeLines - summary.TotalTestCodeLines,
+		TotalTestCodeLines: summary.TotalTestCodeLines,
+		Generated:          newGeneratedResult(summary),
+		ExcludedFiles:      summary.ExcludedFiles,
+		SkippedFiles:       newSkippedFiles(summary),
+		Commit:             j.Commit,
+		Rules:              j.Rules,
+		Results:            []fileResult{},
 	}
 
 	for _, r := range summary.Results {
@@ -82,12 +140,56 @@ func (j JsonReporter) GenerateReportByFile(summary *sorter.SortedSummary) error
 			BlankLines: r.BlankLines,
 			Comments:   r.Comments,
 			CodeLines:  r.CodeLines,
+			Kind:       r.Kind,
+			Generated:  r.Generated,
+			Regions:    newRegionResults(r.Regions),
+			Encoding:   r.Encoding,
 		})
 	}
 
 	return j.writeJson(jsonReport)
 }
 
+func newRegionResults(regions []scanner.RegionResult) []regionResult {
+	var results []regionResult
+	for _, region := range regions {
+		results = append(results, regionResult{
+			Language:   region.Language,
+			Lines:      region.Lines,
+			BlankLines: region.BlankLines,
+			Comments:   region.Comments,
+			CodeLines:  region.CodeLines,
+		})
+	}
+
+	return results
+}
+
+func newSkippedFiles(summary *sorter.SortedSummary) []skippedFile {
+	var files []skippedFile
+	for _, skipped := range summary.SkippedFiles {
+		files = append(files, skippedFile{File: utils.CleanFileName(skipped.Path), Reason: skipped.Reason})
+	}
+
+	return files
+}
+
+// newGeneratedResult returns the generated code counted apart from the
+// totals, or nil when there is none.
+func newGeneratedResult(summary *sorter.SortedSummary) *generatedResult {
+	if summary.GeneratedFiles == 0 {
+		return nil
+	}
+
+	return &generatedResult{
+		Files:      summary.GeneratedFiles,
+		Lines:      summary.Generated.Lines,
+		BlankLines: summary.Generated.BlankLines,
+		Comments:   summary.Generated.Comments,
+		CodeLines:  summary.Generated.CodeLines,
+	}
+}
+
 func (j JsonReporter) writeJson(jsonReport *report) error {
 	loggers := utils.NewLogger()
 	file, err := json.MarshalIndent(jsonReport, "", "  ")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -35,15 +36,21 @@ type fileResult struct {
 	BlankLines int
 	Comments   int
 	CodeLines  int
+	Kind       string
+	Generated  bool
 }
 
 type report struct {
-	TotalFiles      int
-	TotalLines      int
-	TotalBlankLines int
-	TotalComments   int
-	TotalCodeLines  int
-	Results         interface{}
+	TotalFiles         int
+	TotalLines         int
+	TotalBlankLines    int
+	TotalComments      int
+	TotalCodeLines     int
+	TotalTestCodeLines int
+	Generated          sorter.Result
+	GeneratedFiles     int
+	ExcludedFiles      map[string]int
+	Results            interface{}
 }
 
 type JsonData struct {
@@ -64,12 +71,16 @@ type Result1 struct {
 
 func (p PdfReporter) GenerateReportByLanguage(summary *sorter.SortedSummary) error {
 	pdfReport := &report{
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-		TotalCodeLines:  summary.TotalCodeLines,
-		Results:         []languageResult{},
+		TotalFiles:         summary.TotalFiles,
+		TotalLines:         summary.TotalLines,
+		TotalBlankLines:    summary.TotalBlankLines,
+		TotalComments:      summary.TotalComments,
+		TotalCodeLines:     summary.TotalCodeLines,
+		TotalTestCodeLines: summary.TotalTestCodeLines,
+		Generated:          summary.Generated,
+		GeneratedFiles:     summary.GeneratedFiles,
+		ExcludedFiles:      summary.ExcludedFiles,
+		Results:            []languageResult{},
 	}
 
 	for _, r := range summary.Results {
@@ -88,11 +99,15 @@ func (p PdfReporter) GenerateReportByLanguage(summary *sorter.SortedSummary) err
 
 func (p PdfReporter) GenerateReportByFile(summary *sorter.SortedSummary) error {
 	pdfReport := &report{
-		TotalLines:      summary.TotalLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-		TotalCodeLines:  summary.TotalCodeLines,
-		Results:         []fileResult{},
+		TotalLines:         summary.TotalLines,
+		TotalBlankLines:    summary.TotalBlankLines,
+		TotalComments:      summary.TotalComments,
+		TotalCodeLines:     summary.TotalCodeLines,
+		TotalTestCodeLines: summary.TotalTestCodeLines,
+		Generated:          summary.Generated,
+		GeneratedFiles:     summary.GeneratedFiles,
+		ExcludedFiles:      summary.ExcludedFiles,
+		Results:            []fileResult{},
 	}
 
 	for _, r := range summary.Results {
@@ -103,6 +118,8 @@ func (p PdfReporter) GenerateReportByFile(summary *sorter.SortedSummary) error {
 			BlankLines: r.BlankLines,
 			Comments:   r.Comments,
 			CodeLines:  r.CodeLines,
+			Kind:       r.Kind,
+			Generated:  r.Generated,
 		})
 	}
 
@@ -132,10 +149,12 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 	path := filepath.Join(p.OutputPath+"/", outputName)
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	const (
-		widthFile      = 100 // Width for the "File" column
-		widthColumns   = 24  // Width for other columns (Lines, Blank Lines, Comments, Code Lines)
-		height         = 10  // Line height
-		maxRowsPerPage = 19  // Adjust according to content and font
+		widthFile      = 86 // Width for the "File" column
+		widthColumns   = 24 // Width for other columns (Lines, Blank Lines, Comments, Code Lines)
+		widthKind      = 14 // Width for the "Kind" column
+		maxFileChars   = 63 // File names longer than this are wrapped
+		height         = 10 // Line height
+		maxRowsPerPage = 19 // Adjust according to content and font
 	)
 
 	// First page with title, image and overall statistics
@@ -159,7 +178,7 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 	if fileResults, ok := pdfReport.Results.([]fileResult); ok {
 		jsonCodeLines := 0
 		for _, r := range fileResults {
-			if strings.HasSuffix(strings.ToLower(r.File), ".json") {
+			if !r.Generated && strings.HasSuffix(strings.ToLower(r.File), ".json") {
 				jsonCodeLines += r.CodeLines
 			}
 		}
@@ -176,8 +195,20 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 	pdf.Ln(5)
 	pdf.Cell(0, 10, "Total Code Lines: "+strconv.Itoa(totalCodeLinesForReport))
 	pdf.Ln(5)
+	pdf.Cell(0, 10, "Main Code Lines: "+strconv.Itoa(totalCodeLinesForReport-pdfReport.TotalTestCodeLines))
+	pdf.Ln(5)
+	pdf.Cell(0, 10, "Test Code Lines: "+strconv.Itoa(pdfReport.TotalTestCodeLines))
+	pdf.Ln(5)
+	if pdfReport.GeneratedFiles > 0 {
+		pdf.Cell(0, 10, fmt.Sprintf("Generated Code Lines (not in total): %d in %d files", pdfReport.Generated.CodeLines, pdfReport.GeneratedFiles))
+		pdf.Ln(5)
+	}
+	for _, reason := range sortedReasons(pdfReport.ExcludedFiles) {
+		pdf.Cell(0, 10, fmt.Sprintf("Excluded Files (%s): %d", reason, pdfReport.ExcludedFiles[reason]))
+		pdf.Ln(5)
+	}
 	pdf.SetFont("Times", "", 8)
-	pdf.Cell(0, 8, "Note: "+utils.NoteExcludedFromTotal)
+	pdf.Cell(0, 8, "Note: "+utils.NoteExcludedFromTotal())
 	pdf.Ln(10)
 
 	// Table Headers
@@ -187,12 +218,16 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 	pdf.Cell(widthColumns, height, "Blank Lines")
 	pdf.Cell(widthColumns, height, "Comments")
 	pdf.Cell(widthColumns, height, "Code Lines")
+	pdf.Cell(widthKind, height, "Kind")
 	pdf.Ln(height)
 
 	pdf.Line(10, pdf.GetY(), 200, pdf.GetY())
 	pdf.SetFont("Times", "", 9)
 
 	for i, result := range pdfReport.Results.([]fileResult) {
+		if result.Generated {
+			result.File += " (generated)"
+		}
 		if i%maxRowsPerPage == 0 && i > 0 {
 			pdf.AddPage()
 			pdf.SetFont("Times", "B", 10)
@@ -201,17 +236,18 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 			pdf.Cell(widthColumns, height, "Blank Lines")
 			pdf.Cell(widthColumns, height, "Comments")
 			pdf.Cell(widthColumns, height, "Code Lines")
+			pdf.Cell(widthKind, height, "Kind")
 			pdf.Ln(height)
 
 			pdf.Line(10, pdf.GetY(), 200, pdf.GetY())
 			pdf.SetFont("Times", "", 9)
 		}
 
-		if len(result.File) > 73 {
+		if len(result.File) > maxFileChars {
 
-			pdf.Cell(widthFile, height, result.File[:73])
+			pdf.Cell(widthFile, height, result.File[:maxFileChars])
 			pdf.Ln(height)
-			pdf.Cell(widthFile, height, result.File[73:])
+			pdf.Cell(widthFile, height, result.File[maxFileChars:])
 		} else {
 			pdf.Cell(widthFile, height, result.File)
 		}
@@ -220,6 +256,7 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 		pdf.Cell(widthColumns, height, strconv.Itoa(result.BlankLines))
 		pdf.Cell(widthColumns, height, strconv.Itoa(result.Comments))
 		pdf.Cell(widthColumns, height, strconv.Itoa(result.CodeLines))
+		pdf.Cell(widthKind, height, result.Kind)
 		pdf.Ln(height)
 
 		pdf.Line(10, pdf.GetY(), 200, pdf.GetY())
@@ -233,6 +270,16 @@ func (p PdfReporter) writePdf(pdfReport *report) error {
 	return nil
 }
 
+func sortedReasons(excludedFiles map[string]int) []string {
+	reasons := make([]string, 0, len(excludedFiles))
+	for reason := range excludedFiles {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	return reasons
+}
+
 func (p PdfReporter) GenerateGlobalReportByFile() error {
 	loggers := utils.NewLogger()
 	dir := "./Results/byfile-report/"
@@ -1,7 +1,9 @@
 package prompt
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
 
 	"github.com/SonarSource-Demos/sonar-golc/pkg/sorter"
@@ -45,6 +47,8 @@ func (p PromptReporter) GenerateReportByLanguage(summary *sorter.SortedSummary)
 	})
 
 	table.Render()
+	printGenerated(summary)
+	printExcludedFiles(summary.ExcludedFiles)
 
 	return nil
 }
@@ -62,8 +66,12 @@ func (p PromptReporter) GenerateReportByFile(summary *sorter.SortedSummary) erro
 	table.SetAutoFormatHeaders(false)
 
 	for _, file := range summary.Results {
+		name := file.Name
+		if file.Generated {
+			name += " (generated)"
+		}
 		table.Append([]string{
-			file.Name,
+			name,
 			strconv.Itoa(file.Lines),
 			strconv.Itoa(file.BlankLines),
 			strconv.Itoa(file.Comments),
@@ -80,6 +88,30 @@ func (p PromptReporter) GenerateReportByFile(summary *sorter.SortedSummary) erro
 	})
 
 	table.Render()
+	printGenerated(summary)
+	printExcludedFiles(summary.ExcludedFiles)
 
 	return nil
 }
+
+// printGenerated prints the generated code, which the totals leave out.
+func printGenerated(summary *sorter.SortedSummary) {
+	if summary.GeneratedFiles == 0 {
+		return
+	}
+
+	fmt.Printf("Generated code (not in total): %d files, %d lines, %d code lines\n",
+		summary.GeneratedFiles, summary.Generated.Lines, summary.Generated.CodeLines)
+}
+
+func printExcludedFiles(excludedFiles map[string]int) {
+	reasons := make([]string, 0, len(excludedFiles))
+	for reason := range excludedFiles {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		fmt.Printf("Excluded files (%s): %d\n", reason, excludedFiles[reason])
+	}
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingSniffLength is how much of the head of a file its encoding is
+// detected from.
+const encodingSniffLength = 8000
+
+// lineReader reads the lines of a file decoded to UTF-8. A line ends with
+// "\n", "\r\n" or a lone "\r", as in files of old Mac systems.
+type lineReader struct {
+	reader *bufio.Reader
+	// pending are the lines ended by a lone "\r" not returned yet.
+	pending []string
+	// Encoding is the encoding detected for the file.
+	Encoding string
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	reader := bufio.NewReader(r)
+	head, _ := reader.Peek(encodingSniffLength)
+	encoding := analyzer.DetectEncoding(head)
+
+	switch encoding {
+	case analyzer.EncodingUTF8BOM:
+		_, _ = reader.Discard(3)
+	case analyzer.EncodingUTF16LE:
+		reader = bufio.NewReader(transform.NewReader(reader, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()))
+	case analyzer.EncodingUTF16BE:
+		reader = bufio.NewReader(transform.NewReader(reader, unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()))
+	}
+
+	return &lineReader{reader: reader, Encoding: encoding}
+}
+
+// next returns the next line without its line ending, or io.EOF once every
+// line is read. The last line need not be ended.
+func (l *lineReader) next() (string, error) {
+	if len(l.pending) > 0 {
+		line := l.pending[0]
+		l.pending = l.pending[1:]
+		return line, nil
+	}
+
+	line, err := l.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+
+	lines := strings.Split(line, "\r")
+	l.pending = lines[1:]
+
+	return lines[0], nil
+}
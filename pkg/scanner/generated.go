@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// How the files detected as generated are counted.
+const (
+	// GeneratedInclude counts generated files like hand-written code.
+	GeneratedInclude = "include"
+	// GeneratedSeparate counts generated files in a separate bucket, outside the totals.
+	GeneratedSeparate = "separate"
+	// GeneratedExclude leaves generated files out of the reports.
+	GeneratedExclude = "exclude"
+)
+
+// ExcludedAsGenerated is the reason recorded for the generated files left out.
+const ExcludedAsGenerated = "generated"
+
+const (
+	// generatedHeaderLines is how many lines are searched for a generated marker.
+	generatedHeaderLines = 20
+	// A file is minified when its longest line and its average line are both this long.
+	minifiedMaxLineLength     = 1000
+	minifiedAverageLineLength = 300
+)
+
+// generatedNamePatterns are the file names of well-known code generators.
+var generatedNamePatterns = []string{
+	"*.pb.go", "*.pb.gw.go", "*.pb.cc", "*.pb.h", "*_pb2.py", "*_pb2_grpc.py",
+	"*_pb.js", "*_grpc_pb.js", "*_pb.d.ts", "*.pb.swift", "*.pbobjc.m", "*.pbobjc.h",
+	"zz_generated*.go", "*_generated.go", "*.generated.*",
+	"*.g.cs", "*.g.i.cs", "*.designer.cs", "*.Designer.cs", "*.Designer.vb",
+	"*.min.js", "*.min.mjs", "*.min.css",
+}
+
+// generatedMarkers are the lower-case header comments written by code generators.
+var generatedMarkers = []string{
+	"@generated",
+	"<auto-generated",
+	"generated by the protocol buffer compiler",
+	"openapi-generator",
+	"openapi generator",
+	"swagger-codegen",
+}
+
+func isGeneratedName(path string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range generatedNamePatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasGeneratedMarker(line string) bool {
+	line = strings.ToLower(line)
+	// Covers Go's "// Code generated ... DO NOT EDIT." and its equivalents
+	if strings.Contains(line, "generated") && strings.Contains(line, "do not edit") {
+		return true
+	}
+	for _, marker := range generatedMarkers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generatedDetector classifies a file as generated from its name, the
+// markers in its first lines, or the line lengths of a minified file.
+type generatedDetector struct {
+	generated     bool
+	lines         int
+	nonBlankLines int
+	length        int
+	maxLength     int
+}
+
+func newGeneratedDetector(path string) *generatedDetector {
+	return &generatedDetector{generated: isGeneratedName(path)}
+}
+
+func (d *generatedDetector) addLine(line string) {
+	d.lines++
+	if d.generated || len(line) == 0 {
+		return
+	}
+
+	if d.lines <= generatedHeaderLines && hasGeneratedMarker(line) {
+		d.generated = true
+		return
+	}
+
+	d.nonBlankLines++
+	d.length += len(line)
+	if len(line) > d.maxLength {
+		d.maxLength = len(line)
+	}
+}
+
+func (d *generatedDetector) isGenerated() bool {
+	if d.generated {
+		return true
+	}
+	if d.nonBlankLines == 0 {
+		return false
+	}
+
+	return d.maxLength >= minifiedMaxLineLength && d.length/d.nonBlankLines >= minifiedAverageLineLength
+}
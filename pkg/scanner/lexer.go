@@ -0,0 +1,340 @@
+package scanner
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+type lineKind int
+
+const (
+	blankLine lineKind = iota
+	codeLine
+	commentLine
+)
+
+// defaultQuotes delimit the string literals of the languages that declare
+// none, unless they use them as comment tokens. ' is left out, as it also
+// opens the lifetimes of Rust or the primes of Haskell; the languages using
+// it for strings declare it.
+var defaultQuotes = []string{"\""}
+
+// maxCharLiteral bounds the length of a character literal, as in '\u{1F600}'.
+const maxCharLiteral = 12
+
+// docStringPrefixes are the letters allowed before the quote of a docstring, as in r"""...""".
+const docStringPrefixes = "rRuU"
+
+type delimiter struct {
+	open      string
+	close     string
+	multiLine bool
+	// raw strings have no escape sequences.
+	raw bool
+}
+
+// lexer classifies the lines of a file as code, comment or blank. It follows
+// code and comments on the same line, skips the comment tokens found inside
+// string literals, and keeps the state of a block comment or of a multi-line
+// string from one line to the next, counting the depth of block comments
+// when the language nests them.
+type lexer struct {
+	lineComments    []string
+	blockComments   [][]string
+	strings         []delimiter
+	docStringScopes []string
+	nested          bool
+
+	// state of the block comment being read
+	depth int
+	open  string
+	close string
+
+	// state of the string literal being read
+	str       *delimiter
+	docString bool
+
+	// expectDocString is set where a string would be a docstring. While the
+	// header of a docstring scope is read, scopeDepth counts its open brackets.
+	expectDocString bool
+	inScopeHeader   bool
+	scopeDepth      int
+}
+
+func newLexer(info language.LanguageInfo) *lexer {
+	l := &lexer{
+		lineComments:    info.LineComments,
+		blockComments:   info.MultiLineComments,
+		docStringScopes: info.DocStringScopes,
+		nested:          info.NestedComments,
+		expectDocString: len(info.DocStringScopes) > 0,
+	}
+
+	// Multi-line strings come first so that """ wins over ".
+	for _, multiLineString := range info.MultiLineStrings {
+		raw := len(multiLineString) > 2 && multiLineString[2] == language.RawString
+		l.strings = append(l.strings, delimiter{multiLineString[0], multiLineString[1], true, raw})
+	}
+
+	quotes := info.StringDelimiters
+	if len(quotes) == 0 {
+		for _, quote := range defaultQuotes {
+			if !l.isCommentToken(quote) {
+				quotes = append(quotes, quote)
+			}
+		}
+	}
+	for _, quote := range quotes {
+		l.strings = append(l.strings, delimiter{quote, quote, false, false})
+	}
+
+	return l
+}
+
+func (l *lexer) isCommentToken(token string) bool {
+	for _, lineComment := range l.lineComments {
+		if lineComment == token {
+			return true
+		}
+	}
+	for _, blockComment := range l.blockComments {
+		if blockComment[0] == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classify returns the kind of a trimmed line: code as soon as one character
+// is outside a comment or a docstring, comment when the line holds or
+// continues a comment only, and blank otherwise. Blank lines inside a block
+// comment or a docstring are comments.
+func (l *lexer) classify(line string) lineKind {
+	hasCode := false
+	hasComment := l.depth > 0 || (l.str != nil && l.docString)
+	startsStatement := l.depth == 0 && l.str == nil
+	lastCode := -1
+	brackets := 0
+
+	for i := 0; i < len(line); {
+		if l.depth > 0 {
+			i = l.readComment(line, i)
+			continue
+		}
+
+		if l.str != nil {
+			i = l.readString(line, i)
+			if l.docString {
+				hasComment = true
+			} else {
+				hasCode = true
+				lastCode = i - 1
+			}
+			continue
+		}
+
+		rest := line[i:]
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+
+		if open, close, ok := l.blockCommentAt(rest); ok {
+			hasComment = true
+			l.depth, l.open, l.close = 1, open, close
+			i += len(open)
+			continue
+		}
+
+		if l.lineCommentAt(rest) {
+			hasComment = true
+			break
+		}
+
+		if startsStatement && !hasCode && l.expectDocString {
+			if prefix, str, ok := l.docStringAt(rest); ok {
+				hasComment = true
+				l.str, l.docString = str, true
+				l.expectDocString = false
+				i += prefix + len(str.open)
+				continue
+			}
+		}
+
+		hasCode = true
+		if str, ok := l.stringAt(rest); ok {
+			l.str, l.docString = str, false
+			i += len(str.open)
+			lastCode = i - 1
+			continue
+		}
+		if n := charLiteralAt(rest); n > 0 {
+			i += n
+			lastCode = i - 1
+			continue
+		}
+		lastCode = i
+		switch line[i] {
+		case '(', '[', '{':
+			brackets++
+		case ')', ']', '}':
+			brackets--
+		}
+		i++
+	}
+
+	// Only the multi-line strings go on after the end of the line.
+	if l.str != nil && !l.str.multiLine {
+		l.str = nil
+	}
+
+	if hasCode && len(l.docStringScopes) > 0 {
+		l.followScopes(line, lastCode, brackets)
+	}
+
+	switch {
+	case hasCode:
+		return codeLine
+	case hasComment:
+		return commentLine
+	default:
+		return blankLine
+	}
+}
+
+// followScopes sets expectDocString after the code line ending the header
+// of a docstring scope with ":", as in "def f(a,\n b):", and clears it
+// after any other code line.
+func (l *lexer) followScopes(line string, lastCode, brackets int) {
+	if !l.inScopeHeader && l.opensScope(line) {
+		l.inScopeHeader = true
+		l.scopeDepth = 0
+	}
+
+	l.expectDocString = false
+	if !l.inScopeHeader {
+		return
+	}
+
+	l.scopeDepth += brackets
+	if l.scopeDepth > 0 || l.str != nil {
+		return
+	}
+
+	l.inScopeHeader = false
+	l.expectDocString = lastCode >= 0 && line[lastCode] == ':'
+}
+
+func (l *lexer) opensScope(line string) bool {
+	for _, scope := range l.docStringScopes {
+		if strings.HasPrefix(line, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readComment advances through a block comment from line[i], and returns
+// the position after the token it met, or the end of the line.
+func (l *lexer) readComment(line string, i int) int {
+	for ; i < len(line); i++ {
+		rest := line[i:]
+		if strings.HasPrefix(rest, l.close) {
+			l.depth--
+			return i + len(l.close)
+		}
+		if l.nested && strings.HasPrefix(rest, l.open) {
+			l.depth++
+			return i + len(l.open)
+		}
+	}
+
+	return i
+}
+
+// readString advances through a string literal from line[i], and returns
+// the position after its closing token, or the end of the line.
+func (l *lexer) readString(line string, i int) int {
+	for i < len(line) {
+		if line[i] == '\\' && !l.str.raw {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(line[i:], l.str.close) {
+			i += len(l.str.close)
+			l.str = nil
+			return i
+		}
+		i++
+	}
+
+	return len(line)
+}
+
+// charLiteralAt returns the length of the character literal starting s,
+// as in 'a' or '\n', or 0. It reads the quotes of the languages for which '
+// is not a string delimiter.
+func charLiteralAt(s string) int {
+	if len(s) < 3 || s[0] != '\'' || s[1] == '\'' {
+		return 0
+	}
+	if s[1] != '\\' {
+		_, size := utf8.DecodeRuneInString(s[1:])
+		if 1+size < len(s) && s[1+size] == '\'' {
+			return size + 2
+		}
+		return 0
+	}
+
+	end := strings.IndexByte(s[3:], '\'')
+	if end < 0 || end+4 > maxCharLiteral {
+		return 0
+	}
+	return end + 4
+}
+
+func (l *lexer) blockCommentAt(s string) (string, string, bool) {
+	for _, blockComment := range l.blockComments {
+		if strings.HasPrefix(s, blockComment[0]) {
+			return blockComment[0], blockComment[1], true
+		}
+	}
+
+	return "", "", false
+}
+
+func (l *lexer) lineCommentAt(s string) bool {
+	for _, lineComment := range l.lineComments {
+		if strings.HasPrefix(s, lineComment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *lexer) stringAt(s string) (*delimiter, bool) {
+	for i := range l.strings {
+		if strings.HasPrefix(s, l.strings[i].open) {
+			return &l.strings[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// docStringAt returns the length of the prefix and the delimiter of the
+// docstring starting s.
+func (l *lexer) docStringAt(s string) (int, *delimiter, bool) {
+	prefix := 0
+	for prefix < len(s) && prefix < 2 && strings.IndexByte(docStringPrefixes, s[prefix]) >= 0 {
+		prefix++
+	}
+
+	str, ok := l.stringAt(s[prefix:])
+	return prefix, str, ok
+}
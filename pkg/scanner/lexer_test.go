@@ -0,0 +1,225 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+func classifyLines(info language.LanguageInfo, lines []string) []lineKind {
+	l := newLexer(info)
+	kinds := make([]lineKind, 0, len(lines))
+	for _, line := range lines {
+		kinds = append(kinds, l.classify(line))
+	}
+	return kinds
+}
+
+func TestLexerClassify(t *testing.T) {
+	cLike := language.LanguageInfo{
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+	}
+	nested := cLike
+	nested.NestedComments = true
+	python := language.LanguageInfo{
+		LineComments:      []string{"#"},
+		MultiLineComments: [][]string{{"\"\"\"", "\"\"\""}, {"'''", "'''"}},
+	}
+	vb := language.LanguageInfo{LineComments: []string{"'"}}
+	rust := nested
+	javaScript := cLike
+	javaScript.StringDelimiters = []string{"\"", "'"}
+
+	cases := []struct {
+		name  string
+		info  language.LanguageInfo
+		lines []string
+		want  []lineKind
+	}{
+		{
+			"trailing block comment opens a comment",
+			cLike,
+			[]string{"code(); /* start", "still comment", "end */"},
+			[]lineKind{codeLine, commentLine, commentLine},
+		},
+		{
+			"code after a closing token",
+			cLike,
+			[]string{"/* start", "end */ code();", "// only comment"},
+			[]lineKind{commentLine, codeLine, commentLine},
+		},
+		{
+			"mid-line block comment",
+			cLike,
+			[]string{"a := 1 /* one */ + 2", "/* one */ /* two */", "/* one */ b := 2"},
+			[]lineKind{codeLine, commentLine, codeLine},
+		},
+		{
+			"comment tokens inside strings",
+			cLike,
+			[]string{`url := "http://example.com"`, `s := "/* not a comment"`, "x := 1", `c := '"' // quote`, "y := 2"},
+			[]lineKind{codeLine, codeLine, codeLine, codeLine, codeLine},
+		},
+		{
+			"escaped quote in a string",
+			cLike,
+			[]string{`s := "a \" /* b"`, "z := 3"},
+			[]lineKind{codeLine, codeLine},
+		},
+		{
+			"blank lines",
+			cLike,
+			[]string{"", "/*", "", "*/", ""},
+			[]lineKind{blankLine, commentLine, commentLine, commentLine, blankLine},
+		},
+		{
+			"flat comments end at the first closing token",
+			cLike,
+			[]string{"/* a /* b */", "code();"},
+			[]lineKind{commentLine, codeLine},
+		},
+		{
+			"nested comments",
+			nested,
+			[]string{"/* a /* b */", "still comment */", "code();", "/* /* */ */ code();"},
+			[]lineKind{commentLine, commentLine, codeLine, codeLine},
+		},
+		{
+			"quote-delimited block comments",
+			python,
+			[]string{`s = "# not a comment"`, `"""`, "doc", `"""`, "t = 'x' # comment", "# comment"},
+			[]lineKind{codeLine, commentLine, commentLine, commentLine, codeLine, commentLine},
+		},
+		{
+			"lifetimes and character literals",
+			rust,
+			[]string{"fn f(x: &'static str) { /* start", "still comment", "end */", `let c = '"'; // quote`, `let e = '\''; /* one */`, "let b = 2;"},
+			[]lineKind{codeLine, commentLine, commentLine, codeLine, codeLine, codeLine},
+		},
+		{
+			"single quotes declared as string delimiters",
+			javaScript,
+			[]string{"s = '/* not a comment';", "x = 1;"},
+			[]lineKind{codeLine, codeLine},
+		},
+		{
+			"quote used as a comment token",
+			vb,
+			[]string{"' comment", `x = "it's" ' comment`},
+			[]lineKind{commentLine, codeLine},
+		},
+	}
+
+	for _, c := range cases {
+		got := classifyLines(c.info, c.lines)
+		for i := range c.want {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: line %d %q = %v, want %v", c.name, i, c.lines[i], got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestLexerDocStrings(t *testing.T) {
+	python := language.LanguageInfo{
+		LineComments:     []string{"#"},
+		StringDelimiters: []string{"\"", "'"},
+		MultiLineStrings: [][]string{{`"""`, `"""`}, {"'''", "'''"}},
+		DocStringScopes:  []string{"def ", "async def ", "class "},
+	}
+
+	lines := []struct {
+		line string
+		want lineKind
+	}{
+		{`"""Module docstring.`, commentLine},
+		{"", commentLine},
+		{`"""`, commentLine},
+		{"import os", codeLine},
+		{`"""not a docstring"""`, codeLine},
+		{"# comment", commentLine},
+		{"class Greeter:", codeLine},
+		{`    r'''Class docstring.'''`, commentLine},
+		{"    def greet(self,", codeLine},
+		{`              name=":"):`, codeLine},
+		{"        # comment before the docstring", commentLine},
+		{`        """Function`, commentLine},
+		{`        docstring."""`, commentLine},
+		{`        sql = """`, codeLine},
+		{"        SELECT 1 # not a comment", codeLine},
+		{"", blankLine},
+		{`        """`, codeLine},
+		{`        """second string"""`, codeLine},
+		{"    def short(self): return 1", codeLine},
+		{"    if True:", codeLine},
+		{`        "plain string statement"`, codeLine},
+		{"async def run():", codeLine},
+		{`    """Doc.""" ; x = 1`, codeLine},
+	}
+
+	l := newLexer(python)
+	for i, c := range lines {
+		if got := l.classify(strings.TrimSpace(c.line)); got != c.want {
+			t.Errorf("line %d %q = %v, want %v", i, c.line, got, c.want)
+		}
+	}
+}
+
+func TestLexerMultiLineStrings(t *testing.T) {
+	golang := language.LanguageInfo{
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineStrings:  [][]string{{"`", "`", language.RawString}},
+	}
+
+	got := classifyLines(golang, []string{"query := `", "// inside a raw string", "/* also inside", "`", "// comment", "s := \"unterminated", "// comment"})
+	want := []lineKind{codeLine, codeLine, codeLine, codeLine, commentLine, codeLine, commentLine}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLexerRawStrings(t *testing.T) {
+	golang := language.LanguageInfo{
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineStrings:  [][]string{{"`", "`", language.RawString}},
+	}
+	javascript := language.LanguageInfo{
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		MultiLineStrings:  [][]string{{"`", "`"}},
+	}
+
+	cases := []struct {
+		name  string
+		info  language.LanguageInfo
+		lines []string
+		want  []lineKind
+	}{
+		{
+			// A backslash ends a raw string of Go.
+			"Go", golang,
+			[]string{"re := `C:\\`", "// comment", "/* c */", "x := 1"},
+			[]lineKind{codeLine, commentLine, commentLine, codeLine},
+		},
+		{
+			// A backslash escapes the backtick of a template literal.
+			"JavaScript", javascript,
+			[]string{"s = `a\\`", "// inside the template literal", "`"},
+			[]lineKind{codeLine, codeLine, codeLine},
+		},
+	}
+	for _, c := range cases {
+		got := classifyLines(c.info, c.lines)
+		for i := range c.want {
+			if got[i] != c.want[i] {
+				t.Errorf("%s line %d %q = %v, want %v", c.name, i, c.lines[i], got[i], c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+// defaultKernelLanguage is the language of the notebooks that do not name one.
+const defaultKernelLanguage = "Python"
+
+// utf8BOM is the byte order mark some editors save notebooks with, which
+// JSON does not allow.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// notebook is the part of a Jupyter notebook used to count its lines: the
+// cells of nbformat 4, or of the worksheets of nbformat 3.
+type notebook struct {
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells      []notebookCell `json:"cells"`
+	Worksheets []struct {
+		Cells []notebookCell `json:"cells"`
+	} `json:"worksheets"`
+}
+
+type notebookCell struct {
+	CellType string         `json:"cell_type"`
+	Source   notebookSource `json:"source"`
+	Input    notebookSource `json:"input"`
+}
+
+// notebookSource is the text of a cell, saved either as a string or as a
+// list of lines.
+type notebookSource string
+
+func (s *notebookSource) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*s = notebookSource(strings.Join(lines, ""))
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*s = notebookSource(text)
+	return nil
+}
+
+// scanNotebook counts the lines of the code cells of a notebook with the
+// rules of its kernel language, and the lines of its markdown cells as
+// comments. The result is reported under the kernel language followed by
+// language.NotebookSuffix. A notebook that is not valid JSON is skipped.
+func (sc *Scanner) scanNotebook(file analyzer.FileMetadata) (scanResult, error) {
+	result := scanResult{Metadata: file}
+
+	data, err := readFile(file)
+	if err != nil {
+		return result, err
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &nb); err != nil {
+		return scanResult{Metadata: file, Skipped: SkippedInvalidNotebook}, nil
+	}
+
+	kernel, info := sc.kernelLanguage(nb)
+	result.Metadata.Language = kernel + language.NotebookSuffix
+
+	cells := nb.Cells
+	for _, worksheet := range nb.Worksheets {
+		cells = append(cells, worksheet.Cells...)
+	}
+
+	for _, cell := range cells {
+		switch cell.CellType {
+		case "code":
+			source := cell.Source
+			if source == "" {
+				source = cell.Input
+			}
+			lexer := newLexer(info)
+			for _, line := range cellLines(source) {
+				switch lexer.classify(strings.TrimSpace(line)) {
+				case blankLine:
+					result.BlankLines++
+				case commentLine:
+					result.Comments++
+				default:
+					result.CodeLines++
+				}
+			}
+		case "markdown":
+			for _, line := range cellLines(cell.Source) {
+				if strings.TrimSpace(line) == "" {
+					result.BlankLines++
+				} else {
+					result.Comments++
+				}
+			}
+		}
+	}
+
+	result.Lines = result.CodeLines + result.BlankLines + result.Comments
+	return result, nil
+}
+
+// kernelLanguage returns the name and the rules of the language of the
+// notebook kernel, matched against the supported languages by name, then
+// by interpreter and then by extension. An unknown language is reported
+// under its own name with the default rules.
+func (sc *Scanner) kernelLanguage(nb notebook) (string, language.LanguageInfo) {
+	kernel := nb.Metadata.LanguageInfo.Name
+	if kernel == "" {
+		kernel = nb.Metadata.KernelSpec.Language
+	}
+	if kernel == "" {
+		kernel = defaultKernelLanguage
+	}
+
+	for name, info := range sc.SupportedLanguages {
+		if strings.EqualFold(name, kernel) && !info.Notebook {
+			return name, info
+		}
+	}
+
+	names := sortedLanguageNames(sc.SupportedLanguages)
+	for _, name := range names {
+		if claims(sc.SupportedLanguages[name].Shebangs, strings.ToLower(kernel)) {
+			return name, sc.SupportedLanguages[name]
+		}
+	}
+	for _, name := range names {
+		if claims(sc.SupportedLanguages[name].Extensions, "."+strings.ToLower(kernel)) {
+			return name, sc.SupportedLanguages[name]
+		}
+	}
+
+	return kernel, language.LanguageInfo{}
+}
+
+// cellLines splits the text of a cell, which may end with a newline, into lines.
+func cellLines(source notebookSource) []string {
+	if source == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(source), "\n"), "\n")
+}
+
+func claims(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedLanguageNames(languages language.Languages) []string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// readFile reads the whole file, from the file source it was found in.
+func readFile(file analyzer.FileMetadata) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
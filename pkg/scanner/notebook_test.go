@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+var notebookLanguages = language.Languages{
+	"Jupyter Notebook": {Extensions: []string{".ipynb"}, Notebook: true},
+	"Python": {
+		LineComments:     []string{"#"},
+		Extensions:       []string{".py"},
+		Shebangs:         []string{"python"},
+		StringDelimiters: []string{"\"", "'"},
+		MultiLineStrings: [][]string{{`"""`, `"""`}},
+	},
+	"Scala": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		Extensions:        []string{".scala"},
+	},
+}
+
+func TestScanNotebook(t *testing.T) {
+	dir := t.TempDir()
+	python := writeTemplate(t, dir, "analysis.ipynb", "Jupyter Notebook", `{
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}, "language_info": {"name": "python"}},
+ "nbformat": 4,
+ "cells": [
+  {"cell_type": "markdown", "source": ["# Title\n", "\n", "Some *text*"]},
+  {"cell_type": "code", "source": ["import pandas as pd\n", "# load the data\n", "\n", "df = pd.read_csv('a.csv')\n"], "outputs": [{"text": ["not counted\n"]}]},
+  {"cell_type": "code", "source": "df.head()"},
+  {"cell_type": "code", "source": []},
+  {"cell_type": "raw", "source": ["raw text\n"]}
+ ]
+}`)
+	scala := writeTemplate(t, dir, "spark.ipynb", "Jupyter Notebook", `{
+ "metadata": {"language_info": {"name": "scala"}},
+ "cells": [{"cell_type": "code", "source": ["// comment\n", "val x = 1\n"]}]
+}`)
+	unknown := writeTemplate(t, dir, "stats.ipynb", "Jupyter Notebook", `{
+ "metadata": {"kernelspec": {"language": "R"}},
+ "cells": [{"cell_type": "code", "source": ["x <- 1\n"]}]
+}`)
+	legacy := writeTemplate(t, dir, "legacy.ipynb", "Jupyter Notebook", `{
+ "worksheets": [{"cells": [{"cell_type": "code", "input": ["print(1)\n", "print(2)\n"]}]}]
+}`)
+
+	sc := NewScanner(notebookLanguages)
+	cases := []struct {
+		file                            analyzer.FileMetadata
+		language                        string
+		code, comments, blank, allLines int
+	}{
+		{python, "Python (notebook)", 3, 3, 2, 8},
+		{scala, "Scala (notebook)", 1, 1, 0, 2},
+		{unknown, "R (notebook)", 1, 0, 0, 1},
+		{legacy, "Python (notebook)", 2, 0, 0, 2},
+	}
+	for _, c := range cases {
+		result, err := sc.scanFile(c.file)
+		if err != nil {
+			t.Fatalf("scanFile(%s): %v", c.file.FilePath, err)
+		}
+		if result.Metadata.Language != c.language || result.CodeLines != c.code || result.Comments != c.comments ||
+			result.BlankLines != c.blank || result.Lines != c.allLines {
+			t.Errorf("%s: got %+v, want %s with %d code, %d comment and %d blank lines", filepath.Base(c.file.FilePath), result, c.language, c.code, c.comments, c.blank)
+		}
+	}
+
+	bom := writeTemplate(t, dir, "bom.ipynb", "Jupyter Notebook", "\xEF\xBB\xBF"+`{"cells": [{"cell_type": "code", "source": ["x = 1\n"]}]}`)
+	if result, err := sc.scanFile(bom); err != nil || result.Skipped != "" || result.CodeLines != 1 {
+		t.Errorf("bom.ipynb = %+v, %v, want 1 code line", result, err)
+	}
+
+	invalid := map[string]string{
+		"broken.ipynb": "{",
+		"lfs.ipynb":    "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a\nsize 12345\n",
+	}
+	for name, content := range invalid {
+		result, err := sc.scanFile(writeTemplate(t, dir, name, "Jupyter Notebook", content))
+		if err != nil || result.Skipped != SkippedInvalidNotebook {
+			t.Errorf("%s = %+v, %v, want it skipped as %s", name, result, err, SkippedInvalidNotebook)
+		}
+	}
+}
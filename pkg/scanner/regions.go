@@ -0,0 +1,462 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+// RegionResult are the lines of a file counted in one of the languages
+// embedded in it, such as the <script lang="ts"> block of a Vue component.
+type RegionResult struct {
+	Language   string
+	Lines      int
+	CodeLines  int
+	BlankLines int
+	Comments   int
+}
+
+type regionKind int
+
+const (
+	// scriptElement and styleElement are the <script> and <style> elements
+	// of the HTML-like templates. Their tags belong to the template.
+	scriptElement regionKind = iota
+	styleElement
+	// phpBlock is a <?php ... ?> block. Its tags belong to PHP.
+	phpBlock
+	// razorBlock is a @{ ... }, @code { ... } or @functions { ... } block of
+	// C# code, closed by the brace matching its opening one.
+	razorBlock
+)
+
+// template lists the regions of a language embedding other languages.
+// The lines outside of them are counted in outside, or in the language of
+// the file when it is empty.
+type template struct {
+	outside string
+	regions []regionKind
+}
+
+// templates are the languages whose files are split into regions.
+var templates = map[string]template{
+	"HTML":   {regions: []regionKind{scriptElement, styleElement}},
+	"XHTML":  {regions: []regionKind{scriptElement, styleElement}},
+	"Vue":    {regions: []regionKind{scriptElement, styleElement}},
+	"Svelte": {regions: []regionKind{scriptElement, styleElement}},
+	"Razor":  {regions: []regionKind{razorBlock, scriptElement, styleElement}},
+	"PHP":    {outside: "HTML", regions: []regionKind{phpBlock, scriptElement, styleElement}},
+}
+
+// Languages of the embedded regions when their tags do not name one.
+const (
+	defaultScriptLanguage = "JavaScript"
+	defaultStyleLanguage  = "CSS"
+	phpLanguage           = "PHP"
+	razorLanguage         = "C#"
+)
+
+// elementLanguages are the languages named by the lang or type attribute
+// of a <script> or <style> element. Other types, such as text/x-template or
+// application/ld+json, keep the element in the template.
+var elementLanguages = map[string]string{
+	"js":                     "JavaScript",
+	"jsx":                    "JavaScript",
+	"javascript":             "JavaScript",
+	"module":                 "JavaScript",
+	"text/javascript":        "JavaScript",
+	"application/javascript": "JavaScript",
+	"text/babel":             "JavaScript",
+	"ts":                     "TypeScript",
+	"tsx":                    "TypeScript",
+	"typescript":             "TypeScript",
+	"text/typescript":        "TypeScript",
+	"application/typescript": "TypeScript",
+	"css":                    "CSS",
+	"text/css":               "CSS",
+	"scss":                   "Scss",
+	"text/scss":              "Scss",
+}
+
+var elementAttribute = regexp.MustCompile(`(?i)\b(lang|type)\s*=\s*["']?([^"'\s>]+)`)
+
+// razorBlockStarts open the blocks of C# code of Razor.
+var razorBlockStarts = []string{"@{", "@code", "@functions"}
+
+// openRegion is the region being read.
+type openRegion struct {
+	kind     regionKind
+	language string
+	// depth counts the open braces of a Razor block, opened tells that its
+	// first brace was read.
+	depth  int
+	opened bool
+}
+
+// splitter classifies the lines of a template with the language of the
+// region they belong to, and counts them by language. A line belongs to
+// the region it starts in, except that the tags of the <script> and
+// <style> elements belong to the template and the tags of the PHP and
+// Razor blocks to the block.
+type splitter struct {
+	languages language.Languages
+	template  template
+	outside   string
+
+	region *openRegion
+	// tag accumulates an opening <script> or <style> tag spanning lines.
+	tag     string
+	tagKind regionKind
+
+	lexers map[string]*lexer
+	counts map[string]*RegionResult
+}
+
+func newSplitter(languages language.Languages, fileLanguage string, t template) *splitter {
+	outside := fileLanguage
+	if _, ok := languages[t.outside]; ok {
+		outside = t.outside
+	}
+
+	s := &splitter{
+		languages: languages,
+		template:  t,
+		outside:   outside,
+		lexers:    map[string]*lexer{},
+		counts:    map[string]*RegionResult{},
+	}
+	s.lexers[outside] = newLexer(languages[outside])
+
+	return s
+}
+
+// classify returns the kind of a trimmed line, read with the rules of the
+// language of its region.
+func (s *splitter) classify(line string) lineKind {
+	lang := s.split(line)
+
+	kind := s.lexers[lang].classify(line)
+	count, ok := s.counts[lang]
+	if !ok {
+		count = &RegionResult{Language: lang}
+		s.counts[lang] = count
+	}
+	count.Lines++
+	switch kind {
+	case blankLine:
+		count.BlankLines++
+	case commentLine:
+		count.Comments++
+	default:
+		count.CodeLines++
+	}
+
+	return kind
+}
+
+// split returns the language of the line, and follows the regions opened
+// and closed in it.
+func (s *splitter) split(line string) string {
+	lang := s.current()
+	lower := asciiLower(line)
+
+	i := 0
+	if outside := s.lexers[s.outside]; s.region == nil && s.tag == "" && outside.depth > 0 {
+		// Tags inside a comment of the template open nothing.
+		if i = s.skipComment(lower, 0, outside.close); i < 0 {
+			return lang
+		}
+	}
+
+	for i < len(line) {
+		switch {
+		case s.tag != "":
+			end := strings.IndexByte(line[i:], '>')
+			if end < 0 {
+				s.tag += " " + line[i:]
+				i = len(line)
+				continue
+			}
+			s.tag += " " + line[i:i+end]
+			s.enterElement()
+			i += end + 1
+
+		case s.region == nil:
+			pos, kind, size := s.openAt(lower, i)
+			if pos < 0 {
+				return lang
+			}
+			if open, close, ok := s.commentBefore(lower, i, pos); ok {
+				if i = s.skipComment(lower, open, close); i < 0 {
+					return lang
+				}
+				continue
+			}
+			if kind == scriptElement || kind == styleElement {
+				s.tag, s.tagKind = lower[pos:pos+size], kind
+				i = pos + size
+				continue
+			}
+			s.enterBlock(kind, lower[pos:pos+size])
+			if pos == 0 {
+				lang = s.current()
+			}
+			i = pos + size
+
+		default:
+			pos, size := s.closeAt(lower, i)
+			if pos < 0 {
+				return lang
+			}
+			if pos == 0 && (s.region.kind == scriptElement || s.region.kind == styleElement) {
+				lang = s.outside
+			}
+			s.region = nil
+			i = pos + size
+		}
+	}
+
+	return lang
+}
+
+// commentBefore finds a block comment of the template opened in
+// lower[i:pos], and returns its position and its closing token.
+func (s *splitter) commentBefore(lower string, i, pos int) (int, string, bool) {
+	first, close := -1, ""
+	for _, comment := range s.languages[s.outside].MultiLineComments {
+		at := strings.Index(lower[i:pos], asciiLower(comment[0]))
+		if at >= 0 && (first < 0 || i+at < first) {
+			first, close = i+at, comment[1]
+		}
+	}
+
+	return first, close, first >= 0
+}
+
+// skipComment returns the position after the token closing the comment
+// read from i, or -1 when the comment goes on after the line.
+func (s *splitter) skipComment(lower string, i int, close string) int {
+	end := strings.Index(lower[i:], asciiLower(close))
+	if end < 0 {
+		return -1
+	}
+
+	return i + end + len(close)
+}
+
+// asciiLower lower-cases the ASCII letters of s only, so that the offsets
+// found in it are those of s, which strings.ToLower does not keep for
+// letters such as the Kelvin sign.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+
+	return string(b)
+}
+
+// current is the language of the region being read.
+func (s *splitter) current() string {
+	if s.region == nil {
+		return s.outside
+	}
+
+	return s.region.language
+}
+
+// openAt finds the first region opened in lower from i, and returns its
+// position, its kind and the length of its opening token, or -1.
+func (s *splitter) openAt(lower string, i int) (int, regionKind, int) {
+	first, firstKind, firstSize := -1, regionKind(0), 0
+	for _, kind := range s.template.regions {
+		pos, size := openTokenAt(kind, lower[i:])
+		if pos >= 0 && (first < 0 || i+pos < first) {
+			first, firstKind, firstSize = i+pos, kind, size
+		}
+	}
+
+	return first, firstKind, firstSize
+}
+
+func openTokenAt(kind regionKind, s string) (int, int) {
+	switch kind {
+	case scriptElement:
+		return elementAt(s, "<script")
+	case styleElement:
+		return elementAt(s, "<style")
+	case phpBlock:
+		php, echo := strings.Index(s, "<?php"), strings.Index(s, "<?=")
+		if echo >= 0 && (php < 0 || echo < php) {
+			return echo, len("<?=")
+		}
+		return php, len("<?php")
+	case razorBlock:
+		first, size := -1, 0
+		for _, start := range razorBlockStarts {
+			if pos := razorBlockAt(s, start); pos >= 0 && (first < 0 || pos < first) {
+				first, size = pos, len(start)
+			}
+		}
+		return first, size
+	}
+
+	return -1, 0
+}
+
+// razorBlockAt finds start in s, followed by a brace or a space when it
+// is a keyword such as @code.
+func razorBlockAt(s, start string) int {
+	for from := 0; ; {
+		pos := strings.Index(s[from:], start)
+		if pos < 0 {
+			return -1
+		}
+		pos += from
+		end := pos + len(start)
+		if strings.HasSuffix(start, "{") || end == len(s) || s[end] == '{' || s[end] == ' ' || s[end] == '\t' {
+			return pos
+		}
+		from = end
+	}
+}
+
+// elementAt finds the opening tag of an element in s, and not the tag of
+// an element whose name only starts the same way.
+func elementAt(s, tag string) (int, int) {
+	for from := 0; ; {
+		pos := strings.Index(s[from:], tag)
+		if pos < 0 {
+			return -1, 0
+		}
+		pos += from
+		end := pos + len(tag)
+		if end == len(s) || s[end] == '>' || s[end] == ' ' || s[end] == '\t' || s[end] == '/' {
+			return pos, len(tag)
+		}
+		from = end
+	}
+}
+
+// closeAt finds the end of the region being read in lower from i, and
+// returns its position and the length of its closing token, or -1.
+func (s *splitter) closeAt(lower string, i int) (int, int) {
+	var pos int
+	switch s.region.kind {
+	case scriptElement:
+		pos = strings.Index(lower[i:], "</script")
+	case styleElement:
+		pos = strings.Index(lower[i:], "</style")
+	case phpBlock:
+		if pos = strings.Index(lower[i:], "?>"); pos >= 0 {
+			return i + pos, len("?>")
+		}
+		return -1, 0
+	case razorBlock:
+		return s.closeBraceAt(lower, i)
+	}
+	if pos < 0 {
+		return -1, 0
+	}
+
+	end := strings.IndexByte(lower[i+pos:], '>')
+	if end < 0 {
+		end = len(lower) - i - pos - 1
+	}
+	return i + pos, end + 1
+}
+
+// closeBraceAt follows the braces of a Razor block from i, and returns the
+// position of the brace closing the block, or -1.
+func (s *splitter) closeBraceAt(lower string, i int) (int, int) {
+	for ; i < len(lower); i++ {
+		switch lower[i] {
+		case '{':
+			s.region.depth++
+			s.region.opened = true
+		case '}':
+			s.region.depth--
+			if s.region.opened && s.region.depth == 0 {
+				return i, 1
+			}
+		}
+	}
+
+	return -1, 0
+}
+
+// enterElement opens the region of the <script> or <style> element whose
+// opening tag was read, unless its type is not a supported language.
+func (s *splitter) enterElement() {
+	tag, kind := s.tag, s.tagKind
+	s.tag = ""
+	if strings.HasSuffix(strings.TrimSpace(tag), "/") {
+		return
+	}
+
+	lang := defaultScriptLanguage
+	if kind == styleElement {
+		lang = defaultStyleLanguage
+	}
+	if match := elementAttribute.FindStringSubmatch(tag); match != nil {
+		named, ok := elementLanguages[strings.ToLower(match[2])]
+		if !ok {
+			lang = s.outside
+		} else {
+			lang = named
+		}
+	}
+
+	s.enter(kind, lang)
+}
+
+// enterBlock opens a PHP or Razor block from its opening token. A Razor
+// block opened by "@{" has read its first brace.
+func (s *splitter) enterBlock(kind regionKind, token string) {
+	if kind == phpBlock {
+		s.enter(kind, phpLanguage)
+		return
+	}
+
+	s.enter(kind, razorLanguage)
+	if strings.HasSuffix(token, "{") {
+		s.region.depth, s.region.opened = 1, true
+	}
+}
+
+// enter opens a region in lang, read by the lexer of the template when
+// lang is not a supported language.
+func (s *splitter) enter(kind regionKind, lang string) {
+	if _, ok := s.languages[lang]; !ok {
+		lang = s.outside
+	}
+	s.region = &openRegion{kind: kind, language: lang}
+	if lang != s.outside {
+		s.lexers[lang] = newLexer(s.languages[lang])
+	}
+}
+
+// results returns the lines counted by language, or nil when all of them
+// are in the language of the file.
+func (s *splitter) results(fileLanguage string) []RegionResult {
+	if len(s.counts) == 0 {
+		return nil
+	}
+	if _, ok := s.counts[fileLanguage]; ok && len(s.counts) == 1 {
+		return nil
+	}
+
+	results := make([]RegionResult, 0, len(s.counts))
+	for _, count := range s.counts {
+		results = append(results, *count)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Language < results[j].Language
+	})
+
+	return results
+}
@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+var templateLanguages = language.Languages{
+	"C#":         {LineComments: []string{"//"}, MultiLineComments: [][]string{{"/*", "*/"}}},
+	"CSS":        {MultiLineComments: [][]string{{"/*", "*/"}}},
+	"HTML":       {MultiLineComments: [][]string{{"<!--", "-->"}}},
+	"JavaScript": {LineComments: []string{"//"}, MultiLineComments: [][]string{{"/*", "*/"}}},
+	"PHP":        {LineComments: []string{"//", "#"}, MultiLineComments: [][]string{{"/*", "*/"}}},
+	"Razor":      {MultiLineComments: [][]string{{"@*", "*@"}, {"<!--", "-->"}}},
+	"Scss":       {LineComments: []string{"//"}, MultiLineComments: [][]string{{"/*", "*/"}}},
+	"TypeScript": {LineComments: []string{"//"}, MultiLineComments: [][]string{{"/*", "*/"}}},
+	"Vue":        {MultiLineComments: [][]string{{"<!--", "-->"}}},
+}
+
+func TestScanTemplateRegions(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		name, lang, content string
+		want                []RegionResult
+	}{
+		{
+			name: "Component.vue", lang: "Vue",
+			content: "<template>\n  <div>{{ msg }}</div>\n</template>\n\n" +
+				"<script setup\n  lang=\"ts\">\n// state\nconst msg: string = 'hi'\n</script>\n\n" +
+				"<style scoped lang=\"scss\">\n.a { color: red; }\n</style>\n",
+			want: []RegionResult{
+				{Language: "Scss", Lines: 1, CodeLines: 1},
+				{Language: "TypeScript", Lines: 2, CodeLines: 1, Comments: 1},
+				{Language: "Vue", Lines: 10, CodeLines: 8, BlankLines: 2},
+			},
+		},
+		{
+			name: "index.html", lang: "HTML",
+			content: "<html>\n<!-- <script>\nnot a script\n</script> -->\n" +
+				"<script>\nvar a = 1;\n</script>\n" +
+				"<script type=\"text/x-template\">\n<div></div>\n</script>\n" +
+				"<script src=\"app.js\" />\n<style>\n/* style */\np {}\n</style>\n</html>\n",
+			want: []RegionResult{
+				{Language: "CSS", Lines: 2, CodeLines: 1, Comments: 1},
+				{Language: "HTML", Lines: 13, CodeLines: 10, Comments: 3},
+				{Language: "JavaScript", Lines: 1, CodeLines: 1},
+			},
+		},
+		{
+			name: "page.php", lang: "PHP",
+			content: "<html>\n<?php\n// load\n$a = 1;\n?>\n<p><?= $a ?></p>\n<?php echo $a; ?>\n</html>\n",
+			want: []RegionResult{
+				{Language: "HTML", Lines: 3, CodeLines: 3},
+				{Language: "PHP", Lines: 5, CodeLines: 4, Comments: 1},
+			},
+		},
+		{
+			name: "Index.cshtml", lang: "Razor",
+			content: "@model Page\n@{\n    var title = \"Home\";\n    if (x) { y(); }\n}\n<h1>@title</h1>\n" +
+				"@code {\n    // fields\n    int count;\n}\n",
+			want: []RegionResult{
+				{Language: "C#", Lines: 8, CodeLines: 7, Comments: 1},
+				{Language: "Razor", Lines: 2, CodeLines: 2},
+			},
+		},
+		{
+			// The Kelvin sign is longer than the k it lower-cases to.
+			name: "kelvin.html", lang: "HTML",
+			content: "\u212A\u212A\u212A\u212A\u212A\u212A<script>abc\nvar a = 1;\n</script>\n",
+			want: []RegionResult{
+				{Language: "HTML", Lines: 2, CodeLines: 2},
+				{Language: "JavaScript", Lines: 1, CodeLines: 1},
+			},
+		},
+		{
+			name: "closed.html", lang: "HTML",
+			content: "\u212A</p><script src=\"a.js\"></script>\n<p>text</p>\n",
+			want:    nil,
+		},
+	}
+
+	sc := NewScanner(templateLanguages)
+	for _, c := range cases {
+		result, err := sc.scanFile(writeTemplate(t, dir, c.name, c.lang, c.content))
+		if err != nil {
+			t.Fatalf("scanFile(%s): %v", c.name, err)
+		}
+		if !reflect.DeepEqual(result.Regions, c.want) {
+			t.Errorf("%s regions = %+v, want %+v", c.name, result.Regions, c.want)
+		}
+	}
+
+	// A template without embedded blocks is counted as before.
+	plain := writeTemplate(t, dir, "plain.html", "HTML", "<p>\n</p>\n")
+	if result, _ := sc.scanFile(plain); result.Regions != nil || result.CodeLines != 2 {
+		t.Errorf("plain.html = %+v, want 2 code lines and no regions", result)
+	}
+}
+
+func TestScanSummaryCountsTemplateRegions(t *testing.T) {
+	dir := t.TempDir()
+	vue := writeTemplate(t, dir, "App.vue", "Vue", "<template>\n<p/>\n</template>\n<script>\nexport default {}\n</script>\n")
+
+	summary, err := NewScanner(templateLanguages).ScanSummary([]analyzer.FileMetadata{vue}, true)
+	if err != nil {
+		t.Fatalf("ScanSummary error: %v", err)
+	}
+
+	if got := summary.Languages["Vue"]; got == nil || got.CodeLines != 5 {
+		t.Errorf("Vue = %+v, want 5 code lines", got)
+	}
+	if got := summary.Languages["JavaScript"]; got == nil || got.CodeLines != 1 {
+		t.Errorf("JavaScript = %+v, want 1 code line", got)
+	}
+	if summary.FilesByLanguage["Vue"] != 1 || summary.FilesByLanguage["JavaScript"] != 0 || summary.TotalCodeLines != 6 {
+		t.Errorf("unexpected files %v or total %d", summary.FilesByLanguage, summary.TotalCodeLines)
+	}
+	if len(summary.Files) != 1 || len(summary.Files[0].Regions) != 2 || summary.Files[0].CodeLines != 6 {
+		t.Errorf("unexpected by-file results: %+v", summary.Files)
+	}
+}
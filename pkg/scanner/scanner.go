@@ -1,10 +1,11 @@
 package scanner
 
 import (
-	"bufio"
 	"io"
-	"os"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
@@ -13,14 +14,38 @@ import (
 
 type Scanner struct {
 	SupportedLanguages language.Languages
+	// Workers bounds the number of files scanned concurrently.
+	// A value <= 0 uses the number of CPUs.
+	Workers int
+	// Generated tells how the files detected as generated are counted:
+	// GeneratedInclude (the default when empty), GeneratedSeparate or GeneratedExclude.
+	Generated string
+	// MaxLineLength skips the files with a longer line, when positive.
+	MaxLineLength int
 }
 
+// SkippedLongLine is the reason recorded for the files skipped because of
+// a line longer than MaxLineLength.
+const SkippedLongLine = "long-line"
+
+// SkippedInvalidNotebook is the reason recorded for the notebooks that are
+// not valid JSON, such as Git LFS pointers or truncated files.
+const SkippedInvalidNotebook = "invalid-notebook"
+
 type scanResult struct {
 	Metadata   analyzer.FileMetadata
 	Lines      int
 	CodeLines  int
 	BlankLines int
 	Comments   int
+	Generated  bool
+	// Regions split the lines of a template between the languages embedded
+	// in it, when some are not in the language of the file.
+	Regions []RegionResult
+	// Skipped is the reason why the file is not counted, if any.
+	Skipped string
+	// Encoding is the text encoding detected for the file.
+	Encoding string
 }
 
 func NewScanner(languages language.Languages) *Scanner {
@@ -29,20 +54,117 @@ func NewScanner(languages language.Languages) *Scanner {
 	}
 }
 
+type indexedResult struct {
+	index  int
+	result scanResult
+	err    error
+}
+
+// Scan counts the lines of every file and returns the results in file order.
+// On error, only the results of the files before the failing one are returned.
 func (sc *Scanner) Scan(files []analyzer.FileMetadata) ([]scanResult, error) {
-	var results []scanResult
+	results := make([]scanResult, 0, len(files))
+	err := sc.scanEach(files, func(result scanResult) {
+		results = append(results, result)
+	})
+
+	return results, err
+}
+
+// ScanSummary counts the lines of every file and aggregates each result into
+// the summary as soon as it is available. Per-file results are only kept when
+// byFile is set, so a by-language run does not grow with the number of files.
+func (sc *Scanner) ScanSummary(files []analyzer.FileMetadata, byFile bool) (*Summary, error) {
+	summary := newSummary(byFile, sc.Generated)
+	err := sc.scanEach(files, summary.add)
+
+	return summary, err
+}
+
+// scanEach scans files with a bounded pool of workers and hands every result
+// to fn in file order, whatever the completion order is. It stops at the
+// first failing file in that order and returns its error.
+func (sc *Scanner) scanEach(files []analyzer.FileMetadata, fn func(scanResult)) error {
 	progress := sc.createProgressbar(len(files))
+	workers := sc.workerCount(len(files))
+
+	jobs := make(chan int)
+	done := make(chan indexedResult)
+	// window bounds how far workers can get ahead of the oldest pending file,
+	// which also bounds the results waiting to be handed over in order.
+	window := make(chan struct{}, 4*workers+1)
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, err := sc.scanFile(files[i])
+				if err != nil {
+					failed.Store(true)
+				}
+				progress.Add(1)
+				done <- indexedResult{index: i, result: result, err: err}
+			}
+		}()
+	}
 
-	for _, file := range files {
-		result, err := sc.scanFile(file)
-		if err != nil {
-			return results, err
+	go func() {
+		for i := range files {
+			window <- struct{}{}
+			if failed.Load() {
+				break
+			}
+			jobs <- i
 		}
-		progress.Add(1)
-		results = append(results, result)
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	pending := make(map[int]indexedResult)
+	next := 0
+	var firstErr error
+
+	for r := range done {
+		pending[r.index] = r
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-window
+
+			if firstErr != nil {
+				continue
+			}
+			if p.err != nil {
+				firstErr = p.err
+				failed.Store(true)
+				continue
+			}
+			fn(p.result)
+		}
+	}
+
+	return firstErr
+}
+
+func (sc *Scanner) workerCount(files int) int {
+	workers := sc.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > files {
+		workers = files
 	}
 
-	return results, nil
+	return workers
 }
 
 func (sc *Scanner) createProgressbar(max int) *progressbar.ProgressBar {
@@ -121,92 +243,69 @@ func (sc *Scanner) createProgressbar(max int) *progressbar.ProgressBar {
 }*/
 
 func (sc *Scanner) scanFile(file analyzer.FileMetadata) (scanResult, error) {
+	if sc.SupportedLanguages[file.Language].Notebook {
+		return sc.scanNotebook(file)
+	}
+
 	result := scanResult{Metadata: file}
-	isInBlockComment := false
-	var closeBlockCommentToken string
 
-	f, err := os.Open(file.FilePath)
+	f, err := file.Open()
 	if err != nil {
 		return result, err
 	}
 	defer f.Close()
 
-	reader := bufio.NewReader(f)
+	var detector *generatedDetector
+	if sc.detectsGenerated() {
+		detector = newGeneratedDetector(file.FilePath)
+	}
+
+	classify := newLexer(sc.SupportedLanguages[file.Language]).classify
+	var split *splitter
+	if template, ok := templates[file.Language]; ok {
+		split = newSplitter(sc.SupportedLanguages, file.Language, template)
+		classify = split.classify
+	}
+
+	reader := newLineReader(f)
+	result.Encoding = reader.Encoding
 	for {
-		line, err := reader.ReadString('\n')
+		line, err := reader.next()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 			return result, err
 		}
+		if sc.MaxLineLength > 0 && len(line) > sc.MaxLineLength {
+			return scanResult{Metadata: file, Skipped: SkippedLongLine}, nil
+		}
 		line = strings.TrimSpace(line)
-
-		if isInBlockComment {
-			result.Comments++
-			if sc.hasSecondMultiLineComment(line, closeBlockCommentToken) {
-				isInBlockComment = false
-			}
-			continue
+		if detector != nil {
+			detector.addLine(line)
 		}
 
-		if sc.isBlankLine(line) {
+		switch classify(line) {
+		case blankLine:
 			result.BlankLines++
-			continue
-		}
-
-		if ok, secondCommentToken := sc.hasFirstMultiLineComment(file, line); ok {
-			isInBlockComment = true
-			closeBlockCommentToken = secondCommentToken
-			result.Comments++
-			if sc.hasSecondMultiLineComment(line, closeBlockCommentToken) {
-				isInBlockComment = false
-			}
-			continue
-		}
-
-		if sc.hasSingleLineComment(file, line) {
+		case commentLine:
 			result.Comments++
-			continue
+		default:
+			result.CodeLines++
 		}
-
-		result.CodeLines++
 	}
 
 	result.Lines = result.CodeLines + result.BlankLines + result.Comments
-
-	return result, nil
-}
-
-func (sc *Scanner) hasFirstMultiLineComment(file analyzer.FileMetadata, line string) (bool, string) {
-	multiLineComments := sc.SupportedLanguages[file.Language].MultiLineComments
-
-	for _, multiLineComment := range multiLineComments {
-		firstCommentToken := multiLineComment[0]
-		if strings.HasPrefix(line, firstCommentToken) {
-			return true, multiLineComment[1]
-		}
+	if split != nil {
+		result.Regions = split.results(file.Language)
 	}
-
-	return false, ""
-}
-
-func (sc *Scanner) hasSecondMultiLineComment(line, commentToken string) bool {
-	return strings.Contains(line, commentToken)
-}
-
-func (sc *Scanner) hasSingleLineComment(file analyzer.FileMetadata, line string) bool {
-	lineComments := sc.SupportedLanguages[file.Language].LineComments
-
-	for _, lineComment := range lineComments {
-		if strings.HasPrefix(line, lineComment) {
-			return true
-		}
+	if detector != nil {
+		result.Generated = detector.isGenerated()
 	}
 
-	return false
+	return result, nil
 }
 
-func (sc *Scanner) isBlankLine(line string) bool {
-	return len(line) == 0
+func (sc *Scanner) detectsGenerated() bool {
+	return sc.Generated == GeneratedSeparate || sc.Generated == GeneratedExclude
 }
@@ -0,0 +1,273 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+var testLanguages = language.Languages{
+	"Golang": {
+		LineComments:      []string{"//"},
+		MultiLineComments: [][]string{{"/*", "*/"}},
+		Extensions:        []string{".go"},
+	},
+}
+
+// helper to write a file of language lang in dir and return its metadata
+func writeTemplate(t *testing.T, dir, name, lang, content string) analyzer.FileMetadata {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return analyzer.FileMetadata{FilePath: path, Extension: filepath.Ext(name), Language: lang}
+}
+
+func TestScanKeepsFileOrder(t *testing.T) {
+	dir := t.TempDir()
+	var files []analyzer.FileMetadata
+	for i := 0; i < 50; i++ {
+		content := ""
+		for j := 0; j <= i; j++ {
+			content += "x := 1\n"
+		}
+		files = append(files, writeTemplate(t, dir, fmt.Sprintf("f%02d.go", i), "Golang", content))
+	}
+
+	for _, workers := range []int{1, 4, 0} {
+		sc := NewScanner(testLanguages)
+		sc.Workers = workers
+		results, err := sc.Scan(files)
+		if err != nil {
+			t.Fatalf("Scan with %d workers: %v", workers, err)
+		}
+		if len(results) != len(files) {
+			t.Fatalf("expected %d results, got %d", len(files), len(results))
+		}
+		for i, r := range results {
+			if r.Metadata.FilePath != files[i].FilePath || r.CodeLines != i+1 {
+				t.Errorf("workers=%d: result %d = %s/%d, want %s/%d", workers, i, r.Metadata.FilePath, r.CodeLines, files[i].FilePath, i+1)
+			}
+		}
+	}
+}
+
+func TestScanReturnsFirstError(t *testing.T) {
+	dir := t.TempDir()
+	files := []analyzer.FileMetadata{
+		writeTemplate(t, dir, "a.go", "Golang", "a := 1\n"),
+		{FilePath: filepath.Join(dir, "missing.go"), Extension: ".go", Language: "Golang"},
+		writeTemplate(t, dir, "b.go", "Golang", "b := 1\n"),
+	}
+
+	sc := NewScanner(testLanguages)
+	sc.Workers = 3
+	results, err := sc.Scan(files)
+	if err == nil {
+		t.Fatal("expected an error for the missing file")
+	}
+	if len(results) != 1 || results[0].Metadata.FilePath != files[0].FilePath {
+		t.Errorf("expected only the results before the failing file, got %+v", results)
+	}
+}
+
+func TestScanSummaryKeepsFilesOnlyByFile(t *testing.T) {
+	dir := t.TempDir()
+	files := []analyzer.FileMetadata{
+		writeTemplate(t, dir, "a.go", "Golang", "// comment\na := 1\n\n"),
+		writeTemplate(t, dir, "b.go", "Golang", "/* block\n end */\nb := 1\n"),
+	}
+
+	sc := NewScanner(testLanguages)
+	byLanguage, err := sc.ScanSummary(files, false)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if len(byLanguage.Files) != 0 {
+		t.Errorf("expected no per-file results, got %d", len(byLanguage.Files))
+	}
+	if byLanguage.TotalFiles != 2 || byLanguage.TotalCodeLines != 2 || byLanguage.TotalComments != 3 || byLanguage.TotalBlankLines != 1 {
+		t.Errorf("unexpected totals: %+v", byLanguage)
+	}
+
+	byFile, err := sc.ScanSummary(files, true)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if len(byFile.Files) != 2 || byFile.Files[0].Path != files[0].FilePath || byFile.Files[1].Path != files[1].FilePath {
+		t.Errorf("unexpected per-file results: %+v", byFile.Files)
+	}
+	if *byFile.Languages["Golang"] != *byLanguage.Languages["Golang"] {
+		t.Errorf("language totals differ: %+v vs %+v", byFile.Languages["Golang"], byLanguage.Languages["Golang"])
+	}
+}
+
+func TestGeneratedDetector(t *testing.T) {
+	long := strings.Repeat("var a=1;", 200)
+	table := []string{long}
+	for i := 0; i < 10; i++ {
+		table = append(table, "x := 1")
+	}
+	cases := []struct {
+		name  string
+		path  string
+		lines []string
+		want  bool
+	}{
+		{"plain file", "main.go", []string{"package main", "", "func main() {}"}, false},
+		{"protobuf name", "api/v1/api.pb.go", []string{"package v1"}, true},
+		{"minified name", "static/app.min.js", []string{"var a = 1;"}, true},
+		{"go marker", "zz.go", []string{"// Code generated by mockgen. DO NOT EDIT.", "package mocks"}, true},
+		{"auto-generated marker", "Model.cs", []string{"// <auto-generated>", "namespace App {}"}, true},
+		{"marker after header", "late.go", append(make([]string, generatedHeaderLines), "// @generated"), false},
+		{"minified content", "bundle.js", []string{long, long}, true},
+		{"one long line", "table.go", table, false},
+	}
+
+	for _, c := range cases {
+		d := newGeneratedDetector(c.path)
+		for _, line := range c.lines {
+			d.addLine(line)
+		}
+		if got := d.isGenerated(); got != c.want {
+			t.Errorf("%s: isGenerated() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestScanSummaryGeneratedModes(t *testing.T) {
+	dir := t.TempDir()
+	files := []analyzer.FileMetadata{
+		writeTemplate(t, dir, "main.go", "Golang", "package main\n\nfunc main() {}\n"),
+		writeTemplate(t, dir, "mock.go", "Golang", "// Code generated by mockgen. DO NOT EDIT.\npackage main\n"),
+		writeTemplate(t, dir, "api.pb.go", "Golang", "package main\nvar x = 1\n"),
+	}
+
+	sc := NewScanner(testLanguages)
+	included, err := sc.ScanSummary(files, true)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if included.TotalFiles != 3 || included.GeneratedFiles != 0 || included.TotalCodeLines != 5 {
+		t.Errorf("include: unexpected summary %+v", included)
+	}
+
+	sc.Generated = GeneratedSeparate
+	separate, err := sc.ScanSummary(files, true)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if separate.TotalFiles != 1 || separate.TotalCodeLines != 2 || separate.Languages["Golang"].CodeLines != 2 {
+		t.Errorf("separate: unexpected totals %+v", separate)
+	}
+	if separate.GeneratedFiles != 2 || separate.Generated.CodeLines != 3 || separate.Generated.Comments != 1 {
+		t.Errorf("separate: unexpected generated bucket %d %+v", separate.GeneratedFiles, separate.Generated)
+	}
+	if len(separate.Files) != 3 || separate.Files[0].Generated || !separate.Files[1].Generated || !separate.Files[2].Generated {
+		t.Errorf("separate: unexpected per-file results %+v", separate.Files)
+	}
+
+	sc.Generated = GeneratedExclude
+	excluded, err := sc.ScanSummary(files, true)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if excluded.TotalFiles != 1 || excluded.GeneratedFiles != 0 || len(excluded.Files) != 1 {
+		t.Errorf("exclude: unexpected summary %+v", excluded)
+	}
+	if excluded.ExcludedFiles[ExcludedAsGenerated] != 2 {
+		t.Errorf("exclude: excluded files = %v, want 2 generated", excluded.ExcludedFiles)
+	}
+}
+
+func TestScanSummaryCountsTestCode(t *testing.T) {
+	dir := t.TempDir()
+	main := writeTemplate(t, dir, "main.go", "Golang", "package main\n\nfunc main() {}\n")
+	main.Kind = analyzer.KindMain
+	test := writeTemplate(t, dir, "main_test.go", "Golang", "package main\n\n// TestMain\nfunc TestMain() {}\nvar x = 1\n")
+	test.Kind = analyzer.KindTest
+
+	summary, err := NewScanner(testLanguages).ScanSummary([]analyzer.FileMetadata{main, test}, true)
+	if err != nil {
+		t.Fatalf("ScanSummary: %v", err)
+	}
+	if summary.TotalCodeLines != 5 || summary.TotalTestCodeLines != 3 || summary.TestFiles != 1 {
+		t.Errorf("unexpected totals: %+v", summary)
+	}
+	if summary.Languages["Golang"].TestCodeLines != 3 {
+		t.Errorf("unexpected language result: %+v", summary.Languages["Golang"])
+	}
+	if summary.Files[0].Kind != analyzer.KindMain || summary.Files[1].Kind != analyzer.KindTest {
+		t.Errorf("unexpected per-file kinds: %+v", summary.Files)
+	}
+}
+
+func TestScanSummarySkipsLongLines(t *testing.T) {
+	dir := t.TempDir()
+	short := writeTemplate(t, dir, "short.go", "Golang", "package main\n\nvar x = 1\n")
+	long := writeTemplate(t, dir, "long.go", "Golang", "package main\n\nvar x = \""+strings.Repeat("a", 200)+"\"\n")
+
+	sc := NewScanner(testLanguages)
+	sc.MaxLineLength = 100
+	summary, err := sc.ScanSummary([]analyzer.FileMetadata{short, long}, true)
+	if err != nil {
+		t.Fatalf("ScanSummary error: %v", err)
+	}
+
+	if summary.TotalFiles != 1 || summary.TotalCodeLines != 2 || len(summary.Files) != 1 {
+		t.Errorf("only short.go should be counted: %+v", summary)
+	}
+	if len(summary.SkippedFiles) != 1 || summary.SkippedFiles[0].Path != long.FilePath || summary.SkippedFiles[0].Reason != SkippedLongLine {
+		t.Errorf("unexpected skipped files: %+v", summary.SkippedFiles)
+	}
+}
+
+func TestScanSummaryDecodesText(t *testing.T) {
+	utf16 := func(s string, bigEndian bool) string {
+		var b strings.Builder
+		for _, r := range s {
+			if bigEndian {
+				b.WriteByte(0)
+				b.WriteRune(r)
+			} else {
+				b.WriteRune(r)
+				b.WriteByte(0)
+			}
+		}
+		return b.String()
+	}
+	source := "// header\npackage main\r\n\r\nvar x = 1"
+
+	dir := t.TempDir()
+	files := []analyzer.FileMetadata{
+		writeTemplate(t, dir, "utf8.go", "Golang", source),
+		writeTemplate(t, dir, "bom.go", "Golang", "\xef\xbb\xbf"+source),
+		writeTemplate(t, dir, "le.go", "Golang", "\xff\xfe"+utf16(source, false)),
+		writeTemplate(t, dir, "be.go", "Golang", utf16(source, true)),
+		writeTemplate(t, dir, "mac.go", "Golang", strings.ReplaceAll(strings.ReplaceAll(source, "\r\n", "\n"), "\n", "\r")),
+	}
+
+	summary, err := NewScanner(testLanguages).ScanSummary(files, true)
+	if err != nil {
+		t.Fatalf("ScanSummary error: %v", err)
+	}
+
+	if len(summary.Files) != len(files) {
+		t.Fatalf("unexpected files: %+v", summary.Files)
+	}
+	encodings := []string{analyzer.EncodingUTF8, analyzer.EncodingUTF8BOM, analyzer.EncodingUTF16LE, analyzer.EncodingUTF16BE, analyzer.EncodingUTF8}
+	for i, file := range summary.Files {
+		if file.Lines != 4 || file.CodeLines != 2 || file.Comments != 1 || file.BlankLines != 1 {
+			t.Errorf("%s: unexpected counts %+v", filepath.Base(file.Path), file)
+		}
+		if file.Encoding != encodings[i] {
+			t.Errorf("%s: encoding = %s, want %s", filepath.Base(file.Path), file.Encoding, encodings[i])
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package scanner
 
+import "github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
+
 type LanguageResult struct {
 	Lines      int
 	CodeLines  int
 	BlankLines int
 	Comments   int
+	// TestCodeLines is the part of CodeLines found in test files.
+	TestCodeLines int
 }
 
 type FileResult struct {
@@ -13,6 +17,12 @@ type FileResult struct {
 	CodeLines  int
 	BlankLines int
 	Comments   int
+	Kind       string
+	Generated  bool
+	// Regions are the lines of a template by embedded language.
+	Regions []RegionResult
+	// Encoding is the text encoding detected for the file.
+	Encoding string
 }
 
 type Summary struct {
@@ -24,44 +34,140 @@ type Summary struct {
 	TotalCodeLines  int
 	TotalBlankLines int
 	TotalComments   int
+	// TestFiles and TotalTestCodeLines count the test files, which are
+	// also part of the totals above.
+	TestFiles          int
+	TotalTestCodeLines int
+	// Generated and GeneratedFiles count the files detected as generated,
+	// which are kept out of the language results and of the totals.
+	Generated      LanguageResult
+	GeneratedFiles int
+	// ExcludedFiles counts, by reason, the files left out of the reports.
+	ExcludedFiles map[string]int
+	// SkippedFiles are the files not counted because of their size, their
+	// binary content or their line length.
+	SkippedFiles  []analyzer.SkippedFile
+	keepFiles     bool
+	generatedMode string
 }
 
 func (sc *Scanner) Summary(results []scanResult) *Summary {
-	summary := &Summary{
+	summary := newSummary(true, sc.Generated)
+
+	for _, result := range results {
+		summary.add(result)
+	}
+
+	return summary
+}
+
+func newSummary(keepFiles bool, generatedMode string) *Summary {
+	return &Summary{
 		Languages:       make(map[string]*LanguageResult),
 		FilesByLanguage: make(map[string]int),
-		TotalFiles:      len(results),
+		ExcludedFiles:   make(map[string]int),
+		keepFiles:       keepFiles,
+		generatedMode:   generatedMode,
 	}
+}
 
-	for _, result := range results {
-		language := result.Metadata.Language
-		if value, ok := summary.Languages[language]; ok {
-			value.Lines += result.Lines
-			value.CodeLines += result.CodeLines
-			value.BlankLines += result.BlankLines
-			value.Comments += result.Comments
-		} else {
-			summary.Languages[language] = &LanguageResult{
-				Lines:      result.Lines,
-				CodeLines:  result.CodeLines,
-				BlankLines: result.BlankLines,
-				Comments:   result.Comments,
-			}
+// add aggregates one scan result into the language totals, and into the
+// per-file results when the summary keeps them.
+func (summary *Summary) add(result scanResult) {
+	if result.Skipped != "" {
+		summary.SkippedFiles = append(summary.SkippedFiles, analyzer.SkippedFile{Path: result.Metadata.FilePath, Reason: result.Skipped})
+		return
+	}
+	if result.Generated {
+		summary.addGenerated(result)
+		return
+	}
+
+	language := result.Metadata.Language
+	regions := result.Regions
+	if len(regions) == 0 {
+		regions = []RegionResult{{
+			Language:   language,
+			Lines:      result.Lines,
+			CodeLines:  result.CodeLines,
+			BlankLines: result.BlankLines,
+			Comments:   result.Comments,
+		}}
+	}
+	summary.language(language)
+	for _, region := range regions {
+		value := summary.language(region.Language)
+		value.Lines += region.Lines
+		value.CodeLines += region.CodeLines
+		value.BlankLines += region.BlankLines
+		value.Comments += region.Comments
+		if result.Metadata.Kind == analyzer.KindTest {
+			value.TestCodeLines += region.CodeLines
 		}
+	}
 
+	if result.Metadata.Kind == analyzer.KindTest {
+		summary.TestFiles++
+		summary.TotalTestCodeLines += result.CodeLines
+	}
+
+	if summary.keepFiles {
 		summary.Files = append(summary.Files, FileResult{
 			Path:       result.Metadata.FilePath,
 			Lines:      result.Lines,
 			CodeLines:  result.CodeLines,
 			BlankLines: result.BlankLines,
 			Comments:   result.Comments,
+			Kind:       result.Metadata.Kind,
+			Regions:    result.Regions,
+			Encoding:   result.Encoding,
 		})
-		summary.FilesByLanguage[language]++
-		summary.TotalLines += result.Lines
-		summary.TotalCodeLines += result.CodeLines
-		summary.TotalBlankLines += result.BlankLines
-		summary.TotalComments += result.Comments
 	}
+	summary.FilesByLanguage[language]++
+	summary.TotalFiles++
+	summary.TotalLines += result.Lines
+	summary.TotalCodeLines += result.CodeLines
+	summary.TotalBlankLines += result.BlankLines
+	summary.TotalComments += result.Comments
+}
 
-	return summary
+// language returns the totals of a language, created on first use. A
+// template is counted in the files of its own language, even when all its
+// lines are in the languages embedded in it.
+func (summary *Summary) language(language string) *LanguageResult {
+	value, ok := summary.Languages[language]
+	if !ok {
+		value = &LanguageResult{}
+		summary.Languages[language] = value
+	}
+
+	return value
+}
+
+// addGenerated counts a generated file in its own bucket, or only records
+// that it was left out when generated files are excluded.
+func (summary *Summary) addGenerated(result scanResult) {
+	if summary.generatedMode == GeneratedExclude {
+		summary.ExcludedFiles[ExcludedAsGenerated]++
+		return
+	}
+
+	summary.Generated.Lines += result.Lines
+	summary.Generated.CodeLines += result.CodeLines
+	summary.Generated.BlankLines += result.BlankLines
+	summary.Generated.Comments += result.Comments
+	summary.GeneratedFiles++
+
+	if summary.keepFiles {
+		summary.Files = append(summary.Files, FileResult{
+			Path:       result.Metadata.FilePath,
+			Lines:      result.Lines,
+			CodeLines:  result.CodeLines,
+			BlankLines: result.BlankLines,
+			Comments:   result.Comments,
+			Kind:       result.Metadata.Kind,
+			Generated:  true,
+			Encoding:   result.Encoding,
+		})
+	}
 }
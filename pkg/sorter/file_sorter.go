@@ -24,13 +24,7 @@ func (f FileSorter) OrderByLanguage(summary *scanner.Summary) *SortedSummary {
 
 	f.sortByFileName(results)
 
-	return &SortedSummary{
-		Results:         results,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return newSortedSummary(summary, results)
 }
 
 func (f FileSorter) OrderByCodeLines(summary *scanner.Summary) *SortedSummary {
@@ -38,13 +32,7 @@ func (f FileSorter) OrderByCodeLines(summary *scanner.Summary) *SortedSummary {
 
 	f.sortByCodeLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return newSortedSummary(summary, results)
 }
 
 func (f FileSorter) OrderByLines(summary *scanner.Summary) *SortedSummary {
@@ -52,13 +40,7 @@ func (f FileSorter) OrderByLines(summary *scanner.Summary) *SortedSummary {
 
 	f.sortByLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return newSortedSummary(summary, results)
 }
 
 func (f FileSorter) OrderByComments(summary *scanner.Summary) *SortedSummary {
@@ -66,13 +48,7 @@ func (f FileSorter) OrderByComments(summary *scanner.Summary) *SortedSummary {
 
 	f.sortByComments(results)
 
-	return &SortedSummary{
-		Results:         results,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return newSortedSummary(summary, results)
 }
 
 func (f FileSorter) OrderByBlankLines(summary *scanner.Summary) *SortedSummary {
@@ -80,17 +56,11 @@ func (f FileSorter) OrderByBlankLines(summary *scanner.Summary) *SortedSummary {
 
 	f.sortByBlankLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return newSortedSummary(summary, results)
 }
 
 func (f FileSorter) getResults(summary *scanner.Summary) []Result {
-	results := []Result{}
+	results := make([]Result, 0, len(summary.Files))
 
 	for _, result := range summary.Files {
 		results = append(results, Result{
@@ -99,6 +69,10 @@ func (f FileSorter) getResults(summary *scanner.Summary) []Result {
 			CodeLines:  result.CodeLines,
 			BlankLines: result.BlankLines,
 			Comments:   result.Comments,
+			Kind:       result.Kind,
+			Generated:  result.Generated,
+			Regions:    result.Regions,
+			Encoding:   result.Encoding,
 		})
 	}
 
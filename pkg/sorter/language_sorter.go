@@ -26,23 +26,16 @@ func (l LanguageSorter) OrderByLanguage(summary *scanner.Summary) *SortedSummary
 	for _, language := range sortedLanguages {
 		result := summary.Languages[language]
 		results = append(results, Result{
-			Name:       language,
-			Lines:      result.Lines,
-			CodeLines:  result.CodeLines,
-			BlankLines: result.BlankLines,
-			Comments:   result.Comments,
+			Name:          language,
+			Lines:         result.Lines,
+			CodeLines:     result.CodeLines,
+			BlankLines:    result.BlankLines,
+			Comments:      result.Comments,
+			TestCodeLines: result.TestCodeLines,
 		})
 	}
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
 }
 
 func (l LanguageSorter) OrderByCodeLines(summary *scanner.Summary) *SortedSummary {
@@ -50,15 +43,7 @@ func (l LanguageSorter) OrderByCodeLines(summary *scanner.Summary) *SortedSummar
 
 	l.sortByCodeLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
 }
 
 func (l LanguageSorter) OrderByLines(summary *scanner.Summary) *SortedSummary {
@@ -66,15 +51,7 @@ func (l LanguageSorter) OrderByLines(summary *scanner.Summary) *SortedSummary {
 
 	l.sortByLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
 }
 
 func (l LanguageSorter) OrderByComments(summary *scanner.Summary) *SortedSummary {
@@ -82,15 +59,7 @@ func (l LanguageSorter) OrderByComments(summary *scanner.Summary) *SortedSummary
 
 	l.sortByComments(results)
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
 }
 
 func (l LanguageSorter) OrderByBlankLines(summary *scanner.Summary) *SortedSummary {
@@ -98,15 +67,7 @@ func (l LanguageSorter) OrderByBlankLines(summary *scanner.Summary) *SortedSumma
 
 	l.sortByBlankLines(results)
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
 }
 
 func (l LanguageSorter) OrderByFiles(summary *scanner.Summary) *SortedSummary {
@@ -126,15 +87,15 @@ func (l LanguageSorter) OrderByFiles(summary *scanner.Summary) *SortedSummary {
 		})
 	}
 
-	return &SortedSummary{
-		Results:         results,
-		FilesByLanguage: summary.FilesByLanguage,
-		TotalFiles:      summary.TotalFiles,
-		TotalLines:      summary.TotalLines,
-		TotalCodeLines:  summary.TotalCodeLines,
-		TotalBlankLines: summary.TotalBlankLines,
-		TotalComments:   summary.TotalComments,
-	}
+	return l.sortedSummary(summary, results)
+}
+
+func (l LanguageSorter) sortedSummary(summary *scanner.Summary, results []Result) *SortedSummary {
+	sorted := newSortedSummary(summary, results)
+	sorted.FilesByLanguage = summary.FilesByLanguage
+	sorted.TotalFiles = summary.TotalFiles
+
+	return sorted
 }
 
 func (l LanguageSorter) sortLanguages(summary *scanner.Summary) []string {
@@ -158,11 +119,12 @@ func (l LanguageSorter) getResults(summary *scanner.Summary) []Result {
 
 	for language, result := range summary.Languages {
 		results = append(results, Result{
-			Name:       language,
-			Lines:      result.Lines,
-			CodeLines:  result.CodeLines,
-			BlankLines: result.BlankLines,
-			Comments:   result.Comments,
+			Name:          language,
+			Lines:         result.Lines,
+			CodeLines:     result.CodeLines,
+			BlankLines:    result.BlankLines,
+			Comments:      result.Comments,
+			TestCodeLines: result.TestCodeLines,
 		})
 	}
 
@@ -3,6 +3,7 @@ package sorter
 import (
 	"sort"
 
+	"github.com/SonarSource-Demos/sonar-golc/pkg/analyzer"
 	"github.com/SonarSource-Demos/sonar-golc/pkg/scanner"
 )
 
@@ -12,6 +13,14 @@ type Result struct {
 	CodeLines  int
 	BlankLines int
 	Comments   int
+	Kind       string
+	Generated  bool
+	// Regions are the lines of a template by embedded language.
+	Regions []scanner.RegionResult
+	// Encoding is the text encoding detected for a file.
+	Encoding string
+	// TestCodeLines is the part of CodeLines found in test files.
+	TestCodeLines int
 }
 
 type SortedSummary struct {
@@ -22,6 +31,13 @@ type SortedSummary struct {
 	TotalCodeLines  int
 	TotalBlankLines int
 	TotalComments   int
+	// TestFiles and TotalTestCodeLines are the test part of the totals.
+	TestFiles          int
+	TotalTestCodeLines int
+	Generated          Result
+	GeneratedFiles     int
+	ExcludedFiles      map[string]int
+	SkippedFiles       []analyzer.SkippedFile
 }
 
 type Sorter interface {
@@ -36,6 +52,28 @@ type baseSorter struct {
 	sortOrder string
 }
 
+// newSortedSummary carries the totals shared by every sorted view of a summary.
+func newSortedSummary(summary *scanner.Summary, results []Result) *SortedSummary {
+	return &SortedSummary{
+		Results:            results,
+		TotalLines:         summary.TotalLines,
+		TotalCodeLines:     summary.TotalCodeLines,
+		TotalBlankLines:    summary.TotalBlankLines,
+		TotalComments:      summary.TotalComments,
+		TestFiles:          summary.TestFiles,
+		TotalTestCodeLines: summary.TotalTestCodeLines,
+		Generated: Result{
+			Lines:      summary.Generated.Lines,
+			CodeLines:  summary.Generated.CodeLines,
+			BlankLines: summary.Generated.BlankLines,
+			Comments:   summary.Generated.Comments,
+		},
+		GeneratedFiles: summary.GeneratedFiles,
+		ExcludedFiles:  summary.ExcludedFiles,
+		SkippedFiles:   summary.SkippedFiles,
+	}
+}
+
 func (b baseSorter) sortByCodeLines(results []Result) {
 	if b.sortOrder == "ASC" {
 		sort.Slice(results, func(i, j int) bool {
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ResultBranchesFile records the repository and the branch of each result file.
+const ResultBranchesFile = "Results/config/result_branches.json"
+
+// BranchReportFile is where WriteBranchReport writes the branch breakdown.
+const BranchReportFile = "Results/BranchReport.json"
+
+// ResultBranch is the repository and the branch a result file was produced from.
+type ResultBranch struct {
+	Project    string `json:"Project"`
+	Repository string `json:"Repository"`
+	Branch     string `json:"Branch"`
+}
+
+// key identifies the repository of the branch across its result files.
+func (b ResultBranch) key() string {
+	if b.Project == "" {
+		return b.Repository
+	}
+	return b.Project + "/" + b.Repository
+}
+
+// BranchLines are the lines of code of one analyzed branch.
+type BranchLines struct {
+	Branch     string `json:"Branch"`
+	ResultFile string `json:"ResultFile"`
+	CodeLines  int    `json:"CodeLines"`
+	Counted    bool   `json:"Counted"`
+}
+
+// RepositoryBranches are the analyzed branches of a repository, of which
+// only the largest one is counted in the totals.
+type RepositoryBranches struct {
+	Project       string        `json:"Project"`
+	Repository    string        `json:"Repository"`
+	CountedBranch string        `json:"CountedBranch"`
+	CodeLines     int           `json:"CodeLines"`
+	Branches      []BranchLines `json:"Branches"`
+}
+
+// BranchReport is the breakdown of the lines of code by repository and branch.
+type BranchReport struct {
+	TotalCodeLines int                  `json:"TotalCodeLines"`
+	Repositories   []RepositoryBranches `json:"Repositories"`
+}
+
+// SaveResultBranches records the repository and the branch of each result
+// file, by name without extension, in ResultBranchesFile.
+func SaveResultBranches(branches map[string]ResultBranch) error {
+	data, err := json.MarshalIndent(branches, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(ResultBranchesFile, data, 0644)
+}
+
+// loadResultBranches reads ResultBranchesFile, or returns an empty map when
+// there is none.
+func loadResultBranches() map[string]ResultBranch {
+	branches := map[string]ResultBranch{}
+
+	data, err := os.ReadFile(ResultBranchesFile)
+	if err != nil {
+		return branches
+	}
+	if err := json.Unmarshal(data, &branches); err != nil {
+		NewLogger().Errorf("❌ Error decoding JSON %s file : %v", ResultBranchesFile, err)
+	}
+
+	return branches
+}
+
+// LargestBranches groups the lines of code of the result files, by name
+// without extension, by repository and counts the largest branch of each.
+// A result file missing from branches is a repository of its own.
+func LargestBranches(codeLines map[string]int, branches map[string]ResultBranch) BranchReport {
+	byRepository := map[string]*RepositoryBranches{}
+
+	// Result files are grouped in order so that ties go to the same branch on every run.
+	names := make([]string, 0, len(codeLines))
+	for name := range codeLines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		branch, ok := branches[name]
+		if !ok {
+			branch = ResultBranch{Repository: strings.TrimPrefix(name, "Result_")}
+		}
+
+		repository, ok := byRepository[branch.key()]
+		if !ok {
+			repository = &RepositoryBranches{Project: branch.Project, Repository: branch.Repository, CodeLines: -1}
+			byRepository[branch.key()] = repository
+		}
+		repository.Branches = append(repository.Branches, BranchLines{
+			Branch:     branch.Branch,
+			ResultFile: name,
+			CodeLines:  codeLines[name],
+		})
+		if codeLines[name] > repository.CodeLines {
+			repository.CodeLines = codeLines[name]
+			repository.CountedBranch = branch.Branch
+		}
+	}
+
+	report := BranchReport{Repositories: []RepositoryBranches{}}
+	for _, repository := range byRepository {
+		counted := false
+		for i := range repository.Branches {
+			if !counted && repository.Branches[i].CodeLines == repository.CodeLines {
+				repository.Branches[i].Counted = true
+				counted = true
+			}
+		}
+		sort.SliceStable(repository.Branches, func(i, j int) bool {
+			return repository.Branches[i].CodeLines > repository.Branches[j].CodeLines
+		})
+
+		report.TotalCodeLines += repository.CodeLines
+		report.Repositories = append(report.Repositories, *repository)
+	}
+
+	sort.Slice(report.Repositories, func(i, j int) bool {
+		if report.Repositories[i].CodeLines != report.Repositories[j].CodeLines {
+			return report.Repositories[i].CodeLines > report.Repositories[j].CodeLines
+		}
+		return report.Repositories[i].Repository < report.Repositories[j].Repository
+	})
+
+	return report
+}
+
+// CountedResults returns the names of the result files counted in the totals.
+func (r BranchReport) CountedResults() []string {
+	var names []string
+	for _, repository := range r.Repositories {
+		for _, branch := range repository.Branches {
+			if branch.Counted {
+				names = append(names, branch.ResultFile)
+			}
+		}
+	}
+
+	return names
+}
+
+// WriteBranchReport writes the branch breakdown to BranchReportFile.
+func WriteBranchReport(report BranchReport) error {
+	data, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(BranchReportFile, data, 0644); err != nil {
+		return err
+	}
+
+	NewLogger().Infof("✅ Branch breakdown exported to %s", BranchReportFile)
+	return nil
+}
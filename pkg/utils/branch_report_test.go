@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLargestBranches(t *testing.T) {
+	codeLines := map[string]int{
+		"Result_org_api_main":    1000,
+		"Result_org_api_feature": 1200,
+		"Result_org_api_old":     300,
+		"Result_org_web_main":    500,
+		"Result_unknown":         50,
+	}
+	branches := map[string]ResultBranch{
+		"Result_org_api_main":    {Project: "org", Repository: "api", Branch: "main"},
+		"Result_org_api_feature": {Project: "org", Repository: "api", Branch: "feature"},
+		"Result_org_api_old":     {Project: "org", Repository: "api", Branch: "old"},
+		"Result_org_web_main":    {Project: "org", Repository: "web", Branch: "main"},
+	}
+
+	report := LargestBranches(codeLines, branches)
+
+	if report.TotalCodeLines != 1750 {
+		t.Errorf("TotalCodeLines = %d, want 1750", report.TotalCodeLines)
+	}
+	if len(report.Repositories) != 3 {
+		t.Fatalf("expected 3 repositories, got %+v", report.Repositories)
+	}
+
+	api := report.Repositories[0]
+	if api.Repository != "api" || api.CountedBranch != "feature" || api.CodeLines != 1200 || len(api.Branches) != 3 {
+		t.Errorf("unexpected api breakdown: %+v", api)
+	}
+	if !api.Branches[0].Counted || api.Branches[1].Counted || api.Branches[2].Counted {
+		t.Errorf("only the largest branch should be counted: %+v", api.Branches)
+	}
+	if unknown := report.Repositories[2]; unknown.Repository != "unknown" || unknown.CodeLines != 50 {
+		t.Errorf("a result file without branch should be its own repository: %+v", unknown)
+	}
+
+	counted := report.CountedResults()
+	if len(counted) != 3 || counted[0] != "Result_org_api_feature" {
+		t.Errorf("unexpected counted results: %v", counted)
+	}
+}
+
+func TestSaveResultBranches(t *testing.T) {
+	_, cleanup := setupGlobalReportEnv(t)
+	defer cleanup()
+	_ = os.MkdirAll("Results/config", 0755)
+
+	branches := map[string]ResultBranch{"Result_org_api_dev": {Project: "org", Repository: "api", Branch: "dev"}}
+	if err := SaveResultBranches(branches); err != nil {
+		t.Fatalf("SaveResultBranches error: %v", err)
+	}
+	if got := loadResultBranches(); got["Result_org_api_dev"] != branches["Result_org_api_dev"] {
+		t.Errorf("loadResultBranches = %+v", got)
+	}
+	if projects := resultProjects(); projects["Result_org_api_dev"] != [2]string{"org/api", "dev"} {
+		t.Errorf("resultProjects = %+v", projects)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jung-kurt/gofpdf"
@@ -52,7 +53,7 @@ func getTotalCodeLines(languages []LanguageData) int {
 func getTotalCodeLinesExcludingJSON(languages []LanguageData) int {
 	total := 0
 	for _, lang := range languages {
-		if strings.TrimSpace(lang.Language) != LanguageExcludedFromTotalLOC {
+		if !IsExcludedFromTotalLOC(lang.Language) {
 			total += lang.CodeLines
 		}
 	}
@@ -91,8 +92,11 @@ func CreateGlobalReport(directory string) error {
 		return err
 	}
 
+	// The license report is optional
+	license := readLicenseReportFromFile(LicenseReportFile)
+
 	// Create a PDF
-	if err := renderGlobalPDF(languages, ginfo); err != nil {
+	if err := renderGlobalPDF(languages, ginfo, license); err != nil {
 		return err
 	}
 
@@ -189,8 +193,58 @@ func readGlobalInfoFromFile(path string) (Globalinfo, error) {
 	return g, nil
 }
 
-// renderGlobalPDF generates the GlobalReport.pdf from languages and global info.
-func renderGlobalPDF(languages []LanguageData, ginfo Globalinfo) error {
+// readLicenseReportFromFile reads the license report, or returns nil when there is none.
+func readLicenseReportFromFile(path string) *LicenseReport {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var license LicenseReport
+	if err := json.Unmarshal(data, &license); err != nil {
+		NewLogger().Errorf("❌ Error decoding JSON %s file : %v", path, err)
+		return nil
+	}
+	return &license
+}
+
+// renderLicense adds the license sizing to the PDF.
+func renderLicense(pdf *gofpdf.Fpdf, license *LicenseReport) {
+	pdf.SetFont("Times", "B", 12)
+	pdf.SetFillColor(51, 153, 255)
+	pdf.CellFormat(100, 10, "SonarQube License", "0", 1, "", true, 0, "")
+	pdf.SetFont("Times", "", 10)
+	pdf.SetFillColor(102, 178, 255)
+	pdf.CellFormat(100, 10, "Licensed lines of code : "+license.LicensedLinesOfCodeF, "0", 1, "", true, 0, "")
+
+	editions := make([]string, 0, len(license.LinesOfCodeByEdition))
+	for edition := range license.LinesOfCodeByEdition {
+		editions = append(editions, edition)
+	}
+	sort.Slice(editions, func(i, j int) bool {
+		return editionRanks[editions[i]] < editionRanks[editions[j]]
+	})
+	for _, edition := range editions {
+		lines := FormatCodeLines(float64(license.LinesOfCodeByEdition[edition]))
+		pdf.CellFormat(100, 10, fmt.Sprintf("  %s : %s LOC", edition, lines), "0", 1, "", true, 0, "")
+	}
+
+	pdf.CellFormat(100, 10, "Required edition : "+license.RequiredEdition, "0", 1, "", true, 0, "")
+	if tier := license.RecommendedTier; tier != nil {
+		recommended := tier.String()
+		if license.ExceedsLargestTier {
+			recommended += " (exceeded)"
+		}
+		pdf.CellFormat(100, 10, "Recommended license : "+recommended, "0", 1, "", true, 0, "")
+	}
+
+	pdf.SetFont("Times", "", 8)
+	pdf.CellFormat(100, 8, "Note: largest branch of each project, test code excluded.", "0", 1, "L", true, 0, "")
+	pdf.Ln(10)
+}
+
+// renderGlobalPDF generates the GlobalReport.pdf from languages and global
+// info, with the license sizing when there is one.
+func renderGlobalPDF(languages []LanguageData, ginfo Globalinfo, license *LicenseReport) error {
 	var unit = "%"
 	loggers := NewLogger()
 	Org := "Organization : " + ginfo.Organization
@@ -220,9 +274,13 @@ func renderGlobalPDF(languages []LanguageData, ginfo Globalinfo) error {
 	pdf.CellFormat(100, 10, Lrepoloc, "0", 1, "", true, 0, "")
 	pdf.CellFormat(100, 10, NBrepos, "0", 1, "", true, 0, "")
 	pdf.SetFont("Times", "", 8)
-	pdf.CellFormat(100, 8, "Note: "+NoteExcludedFromTotal, "0", 1, "L", true, 0, "")
+	pdf.CellFormat(100, 8, "Note: "+NoteExcludedFromTotal(), "0", 1, "L", true, 0, "")
 	pdf.Ln(10)
 
+	if license != nil {
+		renderLicense(pdf, license)
+	}
+
 	pdf.SetFont("Times", "B", 12)
 	pdf.SetFillColor(51, 153, 255)
 
@@ -247,7 +305,7 @@ func renderGlobalPDF(languages []LanguageData, ginfo Globalinfo) error {
 		}
 
 		totalForPct := getTotalCodeLinesExcludingJSON(languages)
-		if strings.TrimSpace(lang.Language) == LanguageExcludedFromTotalLOC {
+		if IsExcludedFromTotalLOC(lang.Language) {
 			lang.Percentage = 0
 		} else if totalForPct > 0 {
 			lang.Percentage = float64(lang.CodeLines) / float64(totalForPct) * 100
@@ -257,7 +315,7 @@ func renderGlobalPDF(languages []LanguageData, ginfo Globalinfo) error {
 		lang.CodeLinesF = fmt.Sprintf("%d", lang.CodeLines)
 		pdf.SetFont("Times", "", 10)
 		pdflang := fmt.Sprintf("%s : %.2f %s - %s LOC", lang.Language, lang.Percentage, unit, lang.CodeLinesF)
-		if strings.TrimSpace(lang.Language) == LanguageExcludedFromTotalLOC {
+		if IsExcludedFromTotalLOC(lang.Language) {
 			pdflang = fmt.Sprintf("%s : (excluded from total) - %s LOC", lang.Language, lang.CodeLinesF)
 		}
 		pdf.CellFormat(100, 10, pdflang, "0", 1, "", true, 0, "")
@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SonarSource-Demos/sonar-golc/pkg/goloc/language"
+)
+
+// LicenseReportFile is where CreateLicenseReport writes the licensing report.
+const LicenseReportFile = "Results/LicenseReport.json"
+
+// EditionNotAnalyzed groups the languages that no SonarQube edition analyzes.
+const EditionNotAnalyzed = "Not analyzed"
+
+// editionRanks orders the SonarQube editions, each one analyzing the
+// languages of the previous ones.
+var editionRanks = map[string]int{
+	language.EditionCommunity:  1,
+	language.EditionDeveloper:  2,
+	language.EditionEnterprise: 3,
+}
+
+// LicenseTier is a SonarQube license: its edition, the lines of code it
+// covers, 0 meaning no limit, and its price.
+type LicenseTier struct {
+	Edition        string  `json:"Edition"`
+	MaxLinesOfCode int     `json:"MaxLinesOfCode"`
+	Price          float64 `json:"Price,omitempty"`
+}
+
+// String describes the tier as in "Developer up to 500.00K LOC".
+func (tier LicenseTier) String() string {
+	description := tier.Edition
+	if tier.MaxLinesOfCode > 0 {
+		description += " up to " + FormatCodeLines(float64(tier.MaxLinesOfCode)) + " LOC"
+	}
+	if tier.Price > 0 {
+		description += fmt.Sprintf(" - %.2f", tier.Price)
+	}
+
+	return description
+}
+
+// DefaultLicenseTiers are the tiers used when the config sets none.
+var DefaultLicenseTiers = []LicenseTier{
+	{Edition: language.EditionCommunity},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 100000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 250000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 500000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 1000000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 2000000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 5000000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 10000000},
+	{Edition: language.EditionDeveloper, MaxLinesOfCode: 20000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 1000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 2000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 5000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 10000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 20000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 50000000},
+	{Edition: language.EditionEnterprise, MaxLinesOfCode: 100000000},
+}
+
+// LicensedProject is the branch of a project counted for the license.
+type LicensedProject struct {
+	Project     string `json:"Project"`
+	Branch      string `json:"Branch"`
+	LinesOfCode int    `json:"LinesOfCode"`
+	Edition     string `json:"Edition"`
+}
+
+// LicenseReport sizes a SonarQube license the way SonarQube counts lines of
+// code: the largest branch of each project, without test code nor the
+// languages excluded from totals.
+type LicenseReport struct {
+	LicensedLinesOfCode    int               `json:"LicensedLinesOfCode"`
+	LicensedLinesOfCodeF   string            `json:"LicensedLinesOfCodeF"`
+	LinesOfCodeByEdition   map[string]int    `json:"LinesOfCodeByEdition"`
+	TestLinesOfCode        int               `json:"TestLinesOfCode"`
+	ExcludedLinesOfCode    int               `json:"ExcludedLinesOfCode"`
+	NotAnalyzedLinesOfCode int               `json:"NotAnalyzedLinesOfCode"`
+	RequiredEdition        string            `json:"RequiredEdition"`
+	RecommendedTier        *LicenseTier      `json:"RecommendedTier,omitempty"`
+	ExceedsLargestTier     bool              `json:"ExceedsLargestTier,omitempty"`
+	Projects               []LicensedProject `json:"Projects"`
+}
+
+// licenseResult is the part of a by-language result file used for licensing.
+type licenseResult struct {
+	Results []struct {
+		Language      string `json:"Language"`
+		CodeLines     int    `json:"CodeLines"`
+		TestCodeLines int    `json:"TestCodeLines"`
+	} `json:"Results"`
+}
+
+// branchLicense is the licensed size of one result file.
+type branchLicense struct {
+	project, branch string
+	linesOfCode     int
+	byEdition       map[string]int
+	testLinesOfCode int
+	excluded        int
+	notAnalyzed     int
+}
+
+// CreateLicenseReport sizes the license from the Result_*.json files of
+// directory, using editions to find the edition analyzing each language and
+// tiers, or DefaultLicenseTiers when empty, to recommend a license, and
+// writes it to LicenseReportFile.
+func CreateLicenseReport(directory string, editions map[string]string, tiers []LicenseTier) (LicenseReport, error) {
+	loggers := NewLogger()
+
+	branches, err := collectBranchLicenses(directory, editions, resultProjects())
+	if err != nil {
+		loggers.Errorf("❌ Error reading files : %v", err)
+		return LicenseReport{}, err
+	}
+
+	if len(tiers) == 0 {
+		tiers = DefaultLicenseTiers
+	}
+	report := buildLicenseReport(branches, tiers)
+
+	data, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return report, err
+	}
+	if err := os.WriteFile(LicenseReportFile, data, 0644); err != nil {
+		loggers.Errorf("❌ Error writing %s : %v", LicenseReportFile, err)
+		return report, err
+	}
+
+	loggers.Infof("✅ License report exported to %s", LicenseReportFile)
+	return report, nil
+}
+
+// resultProjects maps the name of the result file of each analyzed branch,
+// without extension, to its project and branch, from ResultBranchesFile or
+// else from the analysis file of the platform. It is empty when there is
+// none, as for directories.
+func resultProjects() map[string][2]string {
+	projects := map[string][2]string{}
+
+	platform, data, err := detectPlatformAndReadAnalysis()
+	var analysis AnalysisResult
+	if err == nil && json.Unmarshal(data, &analysis) == nil {
+		for _, branch := range analysis.ProjectBranches {
+			firstPart := getFirstPartForPlatform(platform, branch, branch.RepoSlug)
+			name := fmt.Sprintf("Result_%s_%s_%s", firstPart, branch.RepoSlug, branch.MainBranch)
+			projects[name] = [2]string{firstPart + "/" + branch.RepoSlug, branch.MainBranch}
+		}
+	}
+
+	for name, branch := range loadResultBranches() {
+		projects[name] = [2]string{branch.key(), branch.Branch}
+	}
+
+	return projects
+}
+
+// collectBranchLicenses sizes each Result_*.json file of directory.
+func collectBranchLicenses(directory string, editions map[string]string, projects map[string][2]string) ([]branchLicense, error) {
+	var branches []branchLicense
+
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !isEligibleResultFile(info, path) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var result licenseResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(info.Name(), filepath.Ext(path))
+		branch := branchLicense{project: strings.TrimPrefix(name, "Result_"), byEdition: map[string]int{}}
+		if project, ok := projects[name]; ok {
+			branch.project, branch.branch = project[0], project[1]
+		}
+
+		for _, r := range result.Results {
+			switch edition := editions[strings.TrimSpace(r.Language)]; {
+			case IsExcludedFromTotalLOC(r.Language):
+				branch.excluded += r.CodeLines
+			case edition == "":
+				branch.notAnalyzed += r.CodeLines - r.TestCodeLines
+				branch.testLinesOfCode += r.TestCodeLines
+			default:
+				branch.byEdition[edition] += r.CodeLines - r.TestCodeLines
+				branch.linesOfCode += r.CodeLines - r.TestCodeLines
+				branch.testLinesOfCode += r.TestCodeLines
+			}
+		}
+
+		branches = append(branches, branch)
+		return nil
+	})
+
+	return branches, err
+}
+
+// buildLicenseReport keeps the largest branch of each project and
+// recommends the smallest tier of the required edition covering the total.
+func buildLicenseReport(branches []branchLicense, tiers []LicenseTier) LicenseReport {
+	largest := map[string]branchLicense{}
+	for _, branch := range branches {
+		if current, ok := largest[branch.project]; !ok || branch.linesOfCode > current.linesOfCode {
+			largest[branch.project] = branch
+		}
+	}
+
+	report := LicenseReport{LinesOfCodeByEdition: map[string]int{}, Projects: []LicensedProject{}}
+	for _, branch := range largest {
+		edition := requiredEdition(branch.byEdition)
+		report.Projects = append(report.Projects, LicensedProject{
+			Project:     branch.project,
+			Branch:      branch.branch,
+			LinesOfCode: branch.linesOfCode,
+			Edition:     edition,
+		})
+
+		report.LicensedLinesOfCode += branch.linesOfCode
+		report.TestLinesOfCode += branch.testLinesOfCode
+		report.ExcludedLinesOfCode += branch.excluded
+		report.NotAnalyzedLinesOfCode += branch.notAnalyzed
+		for edition, lines := range branch.byEdition {
+			report.LinesOfCodeByEdition[edition] += lines
+		}
+	}
+	if report.NotAnalyzedLinesOfCode > 0 {
+		report.LinesOfCodeByEdition[EditionNotAnalyzed] = report.NotAnalyzedLinesOfCode
+	}
+
+	sort.Slice(report.Projects, func(i, j int) bool {
+		if report.Projects[i].LinesOfCode != report.Projects[j].LinesOfCode {
+			return report.Projects[i].LinesOfCode > report.Projects[j].LinesOfCode
+		}
+		return report.Projects[i].Project < report.Projects[j].Project
+	})
+
+	report.LicensedLinesOfCodeF = FormatCodeLines(float64(report.LicensedLinesOfCode))
+	report.RequiredEdition = requiredEdition(report.LinesOfCodeByEdition)
+	report.RecommendedTier, report.ExceedsLargestTier = recommendTier(tiers, report.RequiredEdition, report.LicensedLinesOfCode)
+
+	return report
+}
+
+// requiredEdition returns the first edition analyzing all the languages
+// with lines of code, Community when there is none.
+func requiredEdition(byEdition map[string]int) string {
+	required := language.EditionCommunity
+	for edition, lines := range byEdition {
+		if lines > 0 && editionRanks[edition] > editionRanks[required] {
+			required = edition
+		}
+	}
+
+	return required
+}
+
+// recommendTier returns the smallest tier of the edition, or of a higher
+// one, covering the lines of code, or the largest of them and true when
+// none covers them.
+func recommendTier(tiers []LicenseTier, edition string, linesOfCode int) (*LicenseTier, bool) {
+	var candidates []LicenseTier
+	for _, tier := range tiers {
+		if editionRanks[tier.Edition] >= editionRanks[edition] {
+			candidates = append(candidates, tier)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	// Tiers are compared by edition, then by size, no limit being the largest.
+	size := func(tier LicenseTier) int {
+		if tier.MaxLinesOfCode == 0 {
+			return int(^uint(0) >> 1)
+		}
+		return tier.MaxLinesOfCode
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if editionRanks[candidates[i].Edition] != editionRanks[candidates[j].Edition] {
+			return editionRanks[candidates[i].Edition] < editionRanks[candidates[j].Edition]
+		}
+		return size(candidates[i]) < size(candidates[j])
+	})
+
+	for i := range candidates {
+		if size(candidates[i]) >= linesOfCode {
+			return &candidates[i], false
+		}
+	}
+
+	largest := candidates[len(candidates)-1]
+	for _, tier := range candidates {
+		if size(tier) > size(largest) {
+			largest = tier
+		}
+	}
+	return &largest, true
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLicenseResult(t *testing.T, dir, name string, results []map[string]interface{}) {
+	t.Helper()
+	b, _ := json.Marshal(map[string]interface{}{"Results": results})
+	if err := os.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestCreateLicenseReport(t *testing.T) {
+	_, cleanup := setupGlobalReportEnv(t)
+	defer cleanup()
+	resultsDir := filepath.Join("Results", "bylanguage-report")
+	_ = os.MkdirAll(resultsDir, 0755)
+	_ = os.MkdirAll(filepath.Join("Results", "config"), 0755)
+
+	analysis := AnalysisResult{ProjectBranches: []ProjectBranch{
+		{Org: "org", RepoSlug: "api", MainBranch: "main"},
+		{Org: "org", RepoSlug: "api", MainBranch: "release"},
+		{Org: "org", RepoSlug: "legacy", MainBranch: "master"},
+	}}
+	b, _ := json.Marshal(analysis)
+	if err := os.WriteFile("Results/config/analysis_result_github.json", b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeLicenseResult(t, resultsDir, "Result_org_api_main.json", []map[string]interface{}{
+		{"Language": "Java", "CodeLines": 1000, "TestCodeLines": 400},
+		{"Language": "JSON", "CodeLines": 5000},
+	})
+	writeLicenseResult(t, resultsDir, "Result_org_api_release.json", []map[string]interface{}{
+		{"Language": "Java", "CodeLines": 800},
+	})
+	writeLicenseResult(t, resultsDir, "Result_org_legacy_master.json", []map[string]interface{}{
+		{"Language": "COBOL", "CodeLines": 300},
+		{"Language": "Makefile", "CodeLines": 50},
+	})
+
+	editions := map[string]string{"Java": "Community", "JSON": "Community", "COBOL": "Enterprise"}
+	report, err := CreateLicenseReport("Results", editions, nil)
+	if err != nil {
+		t.Fatalf("CreateLicenseReport error: %v", err)
+	}
+
+	// api counts its release branch, larger than main once tests are removed
+	if report.LicensedLinesOfCode != 1100 {
+		t.Errorf("LicensedLinesOfCode = %d, want 1100", report.LicensedLinesOfCode)
+	}
+	if len(report.Projects) != 2 || report.Projects[0].Project != "org/api" || report.Projects[0].Branch != "release" {
+		t.Errorf("unexpected projects: %+v", report.Projects)
+	}
+	if report.LinesOfCodeByEdition["Community"] != 800 || report.LinesOfCodeByEdition["Enterprise"] != 300 || report.LinesOfCodeByEdition[EditionNotAnalyzed] != 50 {
+		t.Errorf("unexpected lines by edition: %+v", report.LinesOfCodeByEdition)
+	}
+	if report.RequiredEdition != "Enterprise" {
+		t.Errorf("RequiredEdition = %s, want Enterprise", report.RequiredEdition)
+	}
+	if tier := report.RecommendedTier; tier == nil || tier.Edition != "Enterprise" || tier.MaxLinesOfCode != 1000000 {
+		t.Errorf("unexpected recommended tier: %+v", tier)
+	}
+	if _, err := os.Stat(LicenseReportFile); err != nil {
+		t.Errorf("expected %s: %v", LicenseReportFile, err)
+	}
+
+	// the global report reads it back
+	if saved := readLicenseReportFromFile(LicenseReportFile); saved == nil || saved.LicensedLinesOfCode != 1100 {
+		t.Errorf("unexpected saved license report: %+v", saved)
+	}
+}
+
+func TestRecommendTier(t *testing.T) {
+	tiers := []LicenseTier{
+		{Edition: "Developer", MaxLinesOfCode: 500000, Price: 10},
+		{Edition: "Developer", MaxLinesOfCode: 100000, Price: 5},
+		{Edition: "Enterprise", MaxLinesOfCode: 1000000, Price: 20},
+		{Edition: "Community"},
+	}
+
+	cases := []struct {
+		edition     string
+		linesOfCode int
+		want        LicenseTier
+		exceeded    bool
+	}{
+		{"Community", 5000000, LicenseTier{Edition: "Community"}, false},
+		{"Developer", 80000, LicenseTier{Edition: "Developer", MaxLinesOfCode: 100000, Price: 5}, false},
+		{"Developer", 300000, LicenseTier{Edition: "Developer", MaxLinesOfCode: 500000, Price: 10}, false},
+		{"Developer", 800000, LicenseTier{Edition: "Enterprise", MaxLinesOfCode: 1000000, Price: 20}, false},
+		{"Enterprise", 3000000, LicenseTier{Edition: "Enterprise", MaxLinesOfCode: 1000000, Price: 20}, true},
+	}
+
+	for _, c := range cases {
+		got, exceeded := recommendTier(tiers, c.edition, c.linesOfCode)
+		if got == nil || *got != c.want || exceeded != c.exceeded {
+			t.Errorf("recommendTier(%s, %d) = %+v, %v, want %+v, %v", c.edition, c.linesOfCode, got, exceeded, c.want, c.exceeded)
+		}
+	}
+}
@@ -1,8 +1,51 @@
 package utils
 
+import (
+	"sort"
+	"strings"
+)
+
 // LanguageExcludedFromTotalLOC is the language name whose lines of code are
 // excluded from report totals to match SonarQube standard behavior.
 const LanguageExcludedFromTotalLOC = "JSON"
 
-// NoteExcludedFromTotal is the note shown in reports when JSON is excluded from total LOC.
-const NoteExcludedFromTotal = "JSON is excluded from the total to reproduce standard SonarQube behavior."
+// languagesExcludedFromTotalLOC are the languages whose lines of code are
+// excluded from report totals, LanguageExcludedFromTotalLOC unless changed
+// by SetLanguagesExcludedFromTotalLOC.
+var languagesExcludedFromTotalLOC = map[string]bool{LanguageExcludedFromTotalLOC: true}
+
+// IsExcludedFromTotalLOC tells whether the lines of code of the language are
+// excluded from report totals.
+func IsExcludedFromTotalLOC(language string) bool {
+	return languagesExcludedFromTotalLOC[strings.TrimSpace(language)]
+}
+
+// SetLanguagesExcludedFromTotalLOC replaces the languages excluded from
+// report totals. An empty list keeps the default.
+func SetLanguagesExcludedFromTotalLOC(languages []string) {
+	if len(languages) == 0 {
+		return
+	}
+
+	excluded := make(map[string]bool, len(languages))
+	for _, language := range languages {
+		excluded[strings.TrimSpace(language)] = true
+	}
+	languagesExcludedFromTotalLOC = excluded
+}
+
+// NoteExcludedFromTotal returns the note shown in reports on the languages
+// excluded from total LOC, as in "JSON is excluded from the total...".
+func NoteExcludedFromTotal() string {
+	languages := make([]string, 0, len(languagesExcludedFromTotalLOC))
+	for language := range languagesExcludedFromTotalLOC {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	if len(languages) == 1 {
+		return languages[0] + " is excluded from the total to reproduce standard SonarQube behavior."
+	}
+	last := len(languages) - 1
+	return strings.Join(languages[:last], ", ") + " and " + languages[last] + " are excluded from the total to reproduce standard SonarQube behavior."
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "testing"
+
+func TestNoteExcludedFromTotal(t *testing.T) {
+	defer func(saved map[string]bool) { languagesExcludedFromTotalLOC = saved }(languagesExcludedFromTotalLOC)
+
+	cases := []struct {
+		languages []string
+		want      string
+	}{
+		{nil, "JSON is excluded from the total to reproduce standard SonarQube behavior."},
+		{[]string{"YAML", "JSON"}, "JSON and YAML are excluded from the total to reproduce standard SonarQube behavior."},
+		{[]string{"XML", "CSS", "JSON"}, "CSS, JSON and XML are excluded from the total to reproduce standard SonarQube behavior."},
+	}
+	for _, c := range cases {
+		SetLanguagesExcludedFromTotalLOC(c.languages)
+		if got := NoteExcludedFromTotal(); got != c.want {
+			t.Errorf("languages %v: note %q, want %q", c.languages, got, c.want)
+		}
+	}
+}
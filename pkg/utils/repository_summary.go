@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 
 	"github.com/jung-kurt/gofpdf"
 )
@@ -182,9 +181,8 @@ func getRepositoryData() ([]RepositoryData, error) {
 			}
 			if json.Unmarshal(langData, &byLang) == nil {
 				for _, r := range byLang.Results {
-					if strings.TrimSpace(r.Language) == LanguageExcludedFromTotalLOC {
-						codeLinesForReport = reportData.TotalCodeLines - r.CodeLines
-						break
+					if IsExcludedFromTotalLOC(r.Language) {
+						codeLinesForReport -= r.CodeLines
 					}
 				}
 			}
@@ -383,7 +381,7 @@ func generateRepositoryPDFReport(summary *RepositorySummaryReport, outputPath st
 		fmt.Sprintf("Total Code Lines: %s", summary.TotalCodeLinesF),
 		fmt.Sprintf("Total Comments: %s", summary.TotalCommentsF),
 		fmt.Sprintf("Total Blank Lines: %s", summary.TotalBlankLinesF),
-		NoteExcludedFromTotal,
+		NoteExcludedFromTotal(),
 	}
 
 	for _, data := range summaryData {